@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spankie/gymshark/services"
+)
+
+// analyze runs the analyze command, it prints which quantities a set of pack
+// sizes ships exactly and how far over it ships the others
+func analyze(args []string, orderService services.OrderService, out io.Writer) error {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	flags.SetOutput(out)
+	sizes := flags.String("pack-sizes", "", "comma separated pack sizes, e.g. 23,31,53, the catalog packs by default")
+	catalog := flags.Int("catalog", 0, "version of the pack catalog to analyse, the active catalog by default")
+	minItems := flags.Int("min-items", 1, "smallest quantity packed")
+	maxItems := flags.Int("max-items", 0, "largest quantity packed, twice the largest pack by default")
+	curve := flags.Bool("curve", false, "print the overshoot and packs of every quantity")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	options := services.AnalysisOptions{CatalogVersion: *catalog, MinItems: *minItems, MaxItems: *maxItems}
+	if *sizes != "" {
+		for _, size := range strings.Split(*sizes, ",") {
+			packSize, err := strconv.Atoi(strings.TrimSpace(size))
+			if err != nil || packSize < 1 {
+				return fmt.Errorf("invalid pack size %q", size)
+			}
+			options.PackSizes = append(options.PackSizes, packSize)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	analysis, err := orderService.AnalyzeCatalog(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "pack sizes %v, %d to %d items\n", analysis.PackSizes, analysis.MinItems, analysis.MaxItems)
+	fmt.Fprintf(out, "gcd: %d\n", analysis.GCD)
+	if analysis.FrobeniusNumber != nil {
+		fmt.Fprintf(out, "frobenius number: %d\n", *analysis.FrobeniusNumber)
+	} else {
+		fmt.Fprintf(out, "frobenius number: none, every multiple of %d ships exactly\n", analysis.GCD)
+	}
+	fmt.Fprintf(out, "quantities not shipped exactly: %d of %d\n", analysis.Unreachable, len(analysis.Curve))
+	fmt.Fprintf(out, "worst overshoot: %d items, first at %d\n", analysis.WorstOvershoot, analysis.WorstOvershootItems)
+	fmt.Fprintf(out, "average overshoot: %.2f items, average packs: %.2f\n", analysis.AverageOvershoot,
+		analysis.AveragePackCount)
+	if !*curve {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nITEMS\tOVERSHOOT\tPACKS")
+	for _, point := range analysis.Curve {
+		fmt.Fprintf(w, "%d\t%d\t%d\n", point.NumberOfItems, point.Overshoot, point.PackCount)
+	}
+
+	return w.Flush()
+}
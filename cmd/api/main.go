@@ -70,6 +70,44 @@ func run(apiServer *http.Server, logger *slog.Logger) {
 	gracefulShutdown(apiServer, logger)
 }
 
+// newServices connects to the database and creates the order service with the packing strategy and limits of conf
+func newServices(conf *config.Configuration, logger *slog.Logger) (database.Service, services.OrderService, error) {
+	dbService, err := database.NewPostgresDBService(
+		conf.DbPort, conf.EnableDBSSL, conf.DbHost,
+		conf.DbUsername, conf.DbPassword, conf.DbName,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating database service: %w", err)
+	}
+
+	strategy, err := services.GetPackingStrategy(conf.PackingStrategy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting packing strategy: %w", err)
+	}
+
+	orderService := services.NewOrderService(dbService, logger, strategy, services.Limits{
+		MaxItems:       conf.MaxOrderItems,
+		MaxTableSize:   conf.MaxPackingTable,
+		PackingTimeout: conf.PackingTimeout,
+	})
+
+	return dbService, orderService, nil
+}
+
+// command runs a command of the api instead of the server
+func command(name string, args []string, dbService database.Service, orderService services.OrderService) error {
+	switch name {
+	case "repack":
+		return repack(args, dbService, orderService, os.Stdout)
+	case "recommend":
+		return recommend(args, orderService, os.Stdout)
+	case "analyze":
+		return analyze(args, orderService, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q, the commands are repack, recommend and analyze", name)
+	}
+}
+
 func main() {
 	conf, err := config.GetConfig()
 	if err != nil {
@@ -83,16 +121,20 @@ func main() {
 		os.Exit(1)
 	}
 
-	dbService, err := database.NewPostgresDBService(
-		conf.DbPort, conf.EnableDBSSL, conf.DbHost,
-		conf.DbUsername, conf.DbPassword, conf.DbName,
-	)
+	dbService, orderService, err := newServices(conf, logger)
 	if err != nil {
-		logger.Error("error creating database service", "error", err)
+		logger.Error("error creating services", "error", err)
 		os.Exit(1)
 	}
 
-	orderService := services.NewOrderService(dbService, logger)
+	if len(os.Args) > 1 {
+		if err := command(os.Args[1], os.Args[2:], dbService, orderService); err != nil {
+			logger.Error("error running command", "command", os.Args[1], "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	appServer := server.NewServer(conf, dbService, orderService, logger)
 
 	run(appServer.NewHTTPServer(), logger)
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spankie/gymshark/services"
+)
+
+// defaultRecommendHistory is the order history catalogs are recommended for when -from is not set
+const defaultRecommendHistory = 365 * 24 * time.Hour
+
+// recommend runs the recommend command, it prints the sets of pack sizes
+// that would have shipped the order history best and the score of the
+// active catalog
+func recommend(args []string, orderService services.OrderService, out io.Writer) error {
+	flags := flag.NewFlagSet("recommend", flag.ContinueOnError)
+	flags.SetOutput(out)
+	from := flags.String("from", "", "only orders created at or after this RFC 3339 time, a year ago by default")
+	to := flags.String("to", "", "only orders created before this RFC 3339 time, now by default")
+	sizes := flags.Int("sizes", 0, "number of pack sizes of each set, as many as the active catalog by default")
+	minSize := flags.Int("min-size", 0, "smallest pack size, the smallest pack of the active catalog by default")
+	maxSize := flags.Int("max-size", 0, "largest pack size, the largest pack of the active catalog by default")
+	step := flags.Int("step", 0, "difference between the pack sizes searched, the smallest size by default")
+	top := flags.Int("top", 0, "number of sets recommended")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	end, err := optionalTime(*to)
+	if err != nil {
+		return err
+	}
+	start, err := optionalTime(*from)
+	if err != nil {
+		return err
+	}
+	options := services.RecommendOptions{To: time.Now(), Sizes: *sizes, MinSize: *minSize, MaxSize: *maxSize,
+		Step: *step, Top: *top}
+	if end != nil {
+		options.To = *end
+	}
+	options.From = options.To.Add(-defaultRecommendHistory)
+	if start != nil {
+		options.From = *start
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	recommendations, err := orderService.RecommendCatalogs(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%d orders of %d items, %d pack size sets searched\n\n", recommendations.Orders,
+		recommendations.ItemsOrdered, recommendations.SetsSearched)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tPACK SIZES\tOVERSHOOT\tOVERSHOOT %\tPACKS")
+	printScore(w, "current", recommendations.Current)
+	for i, score := range recommendations.Recommendations {
+		printScore(w, fmt.Sprint(i+1), score)
+	}
+
+	return w.Flush()
+}
+
+func printScore(w io.Writer, rank string, score services.PackSetScore) {
+	fmt.Fprintf(w, "%s\t%v\t%d\t%.2f\t%d\n", rank, score.PackSizes, score.Overshoot, score.OvershootPercent,
+		score.PackCount)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/services"
+)
+
+// repackProgressInterval is how often the repack command prints the progress of the job
+const repackProgressInterval = 2 * time.Second
+
+// repack runs the repack command, it creates a repack job from the flags in
+// args, or resumes one, and runs it until it is completed. Interrupting the
+// command stops the job, it can be resumed with -resume.
+func repack(args []string, db database.Service, orderService services.OrderService, out io.Writer) error {
+	flags := flag.NewFlagSet("repack", flag.ContinueOnError)
+	flags.SetOutput(out)
+	catalog := flags.Int("catalog", 0, "version of the pack catalog the orders are packed with")
+	mode := flags.String("mode", models.RepackWhatIf, "what_if reports the new packing, apply replaces it")
+	resume := flags.Int("resume", 0, "id of a stopped or failed repack job to resume")
+	createdFrom := flags.String("created-from", "", "only orders created at or after this RFC 3339 time")
+	createdTo := flags.String("created-to", "", "only orders created before this RFC 3339 time")
+	minItems := flags.Int("min-items", 0, "only orders of at least this many items")
+	maxItems := flags.Int("max-items", 0, "only orders of at most this many items")
+	packSize := flags.Int("pack-size", 0, "only orders shipped in packs of this size")
+	status := flags.String("status", models.OrderPending, "only orders with this status")
+	customer := flags.Int("customer", 0, "only orders of this customer")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	jobID := *resume
+	if jobID == 0 {
+		job := &models.RepackJob{
+			CatalogVersion: *catalog,
+			Mode:           *mode,
+			Filter: models.RepackFilter{
+				MinItems:   optionalInt(*minItems),
+				MaxItems:   optionalInt(*maxItems),
+				PackSize:   optionalInt(*packSize),
+				Status:     *status,
+				CustomerID: optionalInt(*customer),
+			},
+		}
+		var err error
+		if job.Filter.CreatedFrom, err = optionalTime(*createdFrom); err != nil {
+			return err
+		}
+		if job.Filter.CreatedTo, err = optionalTime(*createdTo); err != nil {
+			return err
+		}
+		if err := orderService.CreateRepackJob(ctx, job); err != nil {
+			return fmt.Errorf("could not create repack job: %w", err)
+		}
+		jobID = job.ID
+	}
+
+	job, err := orderService.ClaimRepackJob(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("could not start repack job %d: %w", jobID, err)
+	}
+	fmt.Fprintf(out, "repack job %d: %s of %d orders with catalog %d\n", job.ID, job.Mode, job.Total,
+		job.CatalogVersion)
+
+	return runRepack(ctx, job, db, orderService, out)
+}
+
+// runRepack runs a claimed repack job and prints its progress until it is finished
+func runRepack(ctx context.Context, job *models.RepackJob, db database.Service, orderService services.OrderService,
+	out io.Writer) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- orderService.RunRepackJob(ctx, job)
+	}()
+
+	ticker := time.NewTicker(repackProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			printRepackJob(out, job)
+			if errors.Is(err, context.Canceled) {
+				return fmt.Errorf("repack job %d stopped, resume it with -resume %d: %w", job.ID, job.ID, err)
+			}
+			return err
+		case <-ticker.C:
+			// the job is read from the database as it is updated by the goroutine running it
+			progress, err := db.GetRepackJob(ctx, job.ID)
+			if err == nil {
+				fmt.Fprintf(out, "processed %d of %d orders, %d changed, %d failed\n", progress.Processed,
+					progress.Total, progress.Changed, progress.Failed)
+			}
+		}
+	}
+}
+
+func printRepackJob(out io.Writer, job *models.RepackJob) {
+	fmt.Fprintf(out, "repack job %d %s: processed %d of %d orders, %d changed, %d failed\n", job.ID, job.Status,
+		job.Processed, job.Total, job.Changed, job.Failed)
+	fmt.Fprintf(out, "overshoot change: %d items, packs change: %d\n", job.OvershootChange, job.PacksChange)
+	if job.Error != nil {
+		fmt.Fprintf(out, "error: %s\n", *job.Error)
+	}
+}
+
+// optionalInt returns nil for a flag that is not set
+func optionalInt(value int) *int {
+	if value == 0 {
+		return nil
+	}
+
+	return &value
+}
+
+// optionalTime parses an RFC 3339 flag, it returns nil for a flag that is not set
+func optionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q: %w", value, err)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readQuantitiesFile reads the quantities in the first column of a CSV file
+func readQuantitiesFile(name string) ([]int, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	quantities, err := readQuantities(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return quantities, nil
+}
+
+// readQuantities reads the quantities in the first column of CSV rows, a
+// first row that is not a number is a header. Empty lines are skipped.
+func readQuantities(r io.Reader) ([]int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var quantities []int
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return quantities, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value := strings.TrimSpace(record[0])
+		quantity, err := strconv.Atoi(value)
+		if err != nil && row == 1 {
+			continue
+		}
+		if err != nil || quantity < 1 {
+			return nil, fmt.Errorf("row %d: %q is not a positive number", row, value)
+		}
+		quantities = append(quantities, quantity)
+	}
+}
@@ -0,0 +1,145 @@
+// Command packcalc packs quantities of items with the packing strategies of
+// the api, without the api or a database.
+//
+//	packcalc -packs 23,31,53 263 500000
+//	packcalc -input orders.csv -format csv -workers 8 > packed.csv
+//	seq 1 10000 | packcalc -format json
+//
+// Quantities are the arguments, or else the first column of the -input CSV
+// file, or of stdin. A first row that is not a number is a header.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/spankie/gymshark/services"
+)
+
+// config is what packcalc packs and how it writes the results
+type config struct {
+	packs    []services.Pack
+	strategy services.PackingStrategy
+	// maxTable is the most totals checked to pack a quantity, 0 for no limit
+	maxTable  int
+	format    string
+	input     string
+	workers   int
+	arguments []string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout)
+	stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "packcalc: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run packs the quantities selected by args and writes the results to out,
+// nothing is written when ctx ends first
+func run(ctx context.Context, args []string, stdin io.Reader, out io.Writer) error {
+	conf, err := parseFlags(args, out)
+	if err != nil {
+		return err
+	}
+
+	var quantities []int
+	switch {
+	case len(conf.arguments) > 0:
+		quantities, err = parseQuantities(conf.arguments)
+	case conf.input != "":
+		quantities, err = readQuantitiesFile(conf.input)
+	default:
+		quantities, err = readQuantities(stdin)
+	}
+	if err != nil {
+		return err
+	}
+
+	results := packAll(ctx, conf.strategy, conf.packs, conf.maxTable, quantities, conf.workers)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("packing stopped: %w", err)
+	}
+
+	return writeResults(out, conf.format, packSizes(conf.packs), results)
+}
+
+func parseFlags(args []string, out io.Writer) (*config, error) {
+	flags := flag.NewFlagSet("packcalc", flag.ContinueOnError)
+	flags.SetOutput(out)
+	packs := flags.String("packs", "250,500,1000,2000,5000", "comma separated pack sizes")
+	strategy := flags.String("strategy", services.StrategyLeastOvershoot,
+		"packing strategy: least_overshoot, fewest_packs or exact")
+	format := flags.String("format", formatTable, "output format: table, json or csv")
+	input := flags.String("input", "", "CSV file of quantities, stdin when there are no quantities or file")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of quantities packed in parallel")
+	maxTable := flags.Int("max-table", 2_000_000, "most totals checked to pack a quantity, 0 for no limit")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	conf := &config{maxTable: *maxTable, format: *format, input: *input, workers: *workers, arguments: flags.Args()}
+	if !slices.Contains([]string{formatTable, formatJSON, formatCSV}, conf.format) {
+		return nil, fmt.Errorf("unknown format %q, use table, json or csv", conf.format)
+	}
+	if conf.workers < 1 {
+		return nil, fmt.Errorf("workers must be at least 1, got %d", conf.workers)
+	}
+	if conf.maxTable < 0 {
+		return nil, fmt.Errorf("max-table must be at least 0, got %d", conf.maxTable)
+	}
+
+	sizes, err := parseQuantities(strings.Split(*packs, ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pack sizes: %w", err)
+	}
+	slices.Sort(sizes)
+	if len(slices.Compact(sizes)) != len(sizes) {
+		return nil, fmt.Errorf("invalid pack sizes: %s has a size twice", *packs)
+	}
+	for _, size := range sizes {
+		conf.packs = append(conf.packs, services.Pack{Size: size})
+	}
+
+	conf.strategy, err = services.GetPackingStrategy(*strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// parseQuantities parses positive numbers of items
+func parseQuantities(values []string) ([]int, error) {
+	quantities := make([]int, 0, len(values))
+	for _, value := range values {
+		quantity, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || quantity < 1 {
+			return nil, fmt.Errorf("%q is not a positive number", value)
+		}
+		quantities = append(quantities, quantity)
+	}
+
+	return quantities, nil
+}
+
+func packSizes(packs []services.Pack) []int {
+	sizes := make([]int, 0, len(packs))
+	for _, p := range packs {
+		sizes = append(sizes, p.Size)
+	}
+
+	return sizes
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	testcases := []struct {
+		name           string
+		args           []string
+		stdin          string
+		expectedOutput string
+		expectedErr    string
+	}{
+		{name: "quantities as arguments", args: []string{"-packs", "23,31,53", "-format", "csv", "263", "1"},
+			expectedOutput: "number_of_items,pack_23,pack_31,pack_53,total_items,overshoot,pack_count,error\n" +
+				"263,2,7,0,263,0,9,\n1,1,0,0,23,22,1,\n"},
+		{name: "quantities from stdin in parallel", args: []string{"-format", "json", "-workers", "4"},
+			stdin: "items\n251\n12001\n",
+			expectedOutput: `[{"number_of_items":251,"packs":[{"pack_size":500,"quantity":1}],"total_items":500,` +
+				`"overshoot":249,"pack_count":1},{"number_of_items":12001,"packs":[{"pack_size":5000,"quantity":2},` +
+				`{"pack_size":2000,"quantity":1},{"pack_size":250,"quantity":1}],"total_items":12250,"overshoot":249,` +
+				`"pack_count":4}]` + "\n"},
+		{name: "no packing", args: []string{"-strategy", "exact", "-format", "csv", "-packs", "250", "7"},
+			expectedOutput: "number_of_items,pack_250,total_items,overshoot,pack_count,error\n" +
+				"7,0,,,,no packing found for the order: 7 items cannot be packed exactly\n"},
+		{name: "table limit", args: []string{"-packs", "23,31,53", "-max-table", "100", "-format", "csv", "263"},
+			expectedOutput: "number_of_items,pack_23,pack_31,pack_53,total_items,overshoot,pack_count,error\n" +
+				"263,0,0,0,,,,\"packing the order exceeds the computation limits: 316 totals to check, at most 100\"\n"},
+		{name: "invalid quantity", stdin: "12\nmany\n", expectedErr: `row 2: "many" is not a positive number`},
+		{name: "duplicate pack size", args: []string{"-packs", "250,250", "1"}, expectedErr: "has a size twice"},
+		{name: "unknown format", args: []string{"-format", "xml", "1"}, expectedErr: "unknown format"},
+		{name: "negative table limit", args: []string{"-max-table", "-1", "1"}, expectedErr: "max-table"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := run(context.Background(), tc.args, strings.NewReader(tc.stdin), out)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error %q but got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if out.String() != tc.expectedOutput {
+				t.Errorf("expected output\n%s\nbut got\n%s", tc.expectedOutput, out.String())
+			}
+		})
+	}
+}
+
+func TestRunStopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out := &bytes.Buffer{}
+	err := run(ctx, []string{"1", "2", "3"}, strings.NewReader(""), out)
+	if !errors.Is(err, context.Canceled) || out.Len() != 0 {
+		t.Errorf("expected packing to stop without output, got %v and %q", err, out.String())
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// output formats of the results
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+func writeResults(out io.Writer, format string, packSizes []int, results []result) error {
+	switch format {
+	case formatJSON:
+		return json.NewEncoder(out).Encode(results)
+	case formatCSV:
+		return writeCSV(out, packSizes, results)
+	default:
+		return writeTable(out, results)
+	}
+}
+
+func writeTable(out io.Writer, results []result) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ITEMS\tPACKS\tTOTAL ITEMS\tOVERSHOOT\tPACK COUNT")
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%d\t%s\t\t\t\n", r.NumberOfItems, r.Error)
+			continue
+		}
+
+		packs := make([]string, 0, len(r.Packs))
+		for _, p := range r.Packs {
+			packs = append(packs, fmt.Sprintf("%d x %d", p.Quantity, p.PackSize))
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\n", r.NumberOfItems, strings.Join(packs, ", "), r.TotalItems,
+			r.Overshoot, r.PackCount)
+	}
+
+	return w.Flush()
+}
+
+// writeCSV writes a row for each result with a pack_<size> column for the
+// number of packs of each size
+func writeCSV(out io.Writer, packSizes []int, results []result) error {
+	w := csv.NewWriter(out)
+	header := []string{"number_of_items"}
+	for _, size := range packSizes {
+		header = append(header, fmt.Sprintf("pack_%d", size))
+	}
+	header = append(header, "total_items", "overshoot", "pack_count", "error")
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		packs := make(map[int]int, len(r.Packs))
+		for _, p := range r.Packs {
+			packs[p.PackSize] = p.Quantity
+		}
+
+		row := []string{strconv.Itoa(r.NumberOfItems)}
+		for _, size := range packSizes {
+			row = append(row, strconv.Itoa(packs[size]))
+		}
+		if r.Error != "" {
+			row = append(row, "", "", "", r.Error)
+		} else {
+			row = append(row, strconv.Itoa(r.TotalItems), strconv.Itoa(r.Overshoot), strconv.Itoa(r.PackCount), "")
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/spankie/gymshark/services"
+)
+
+// result is how a quantity of items is packed, Error says why it could not be
+type result struct {
+	NumberOfItems int                  `json:"number_of_items"`
+	Packs         []services.PackCount `json:"packs"`
+	TotalItems    int                  `json:"total_items"`
+	Overshoot     int                  `json:"overshoot"`
+	PackCount     int                  `json:"pack_count"`
+	Error         string               `json:"error,omitempty"`
+}
+
+// packAll packs every quantity with workers packing in parallel, checking at
+// most maxTableSize totals for each. The results are in the order of the
+// quantities, the quantities left when ctx ends are not packed.
+func packAll(ctx context.Context, strategy services.PackingStrategy, packs []services.Pack, maxTableSize int,
+	quantities []int, workers int) []result {
+	results := make([]result, len(quantities))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(quantities)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = pack(ctx, strategy, packs, maxTableSize, quantities[i])
+			}
+		}()
+	}
+
+	feed(ctx, next, len(quantities))
+	close(next)
+	wg.Wait()
+
+	return results
+}
+
+// feed sends the index of each of the quantities to next until ctx ends
+func feed(ctx context.Context, next chan<- int, quantities int) {
+	for i := range quantities {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func pack(ctx context.Context, strategy services.PackingStrategy, packs []services.Pack, maxTableSize,
+	numberOfItems int) result {
+	r := result{NumberOfItems: numberOfItems, Packs: []services.PackCount{}}
+	packCount, err := strategy.Pack(ctx, packs, numberOfItems, maxTableSize)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	// largest packs first
+	for _, size := range slices.Backward(slices.Sorted(maps.Keys(packCount))) {
+		r.Packs = append(r.Packs, services.PackCount{PackSize: size, Quantity: packCount[size]})
+		r.TotalItems += size * packCount[size]
+		r.PackCount += packCount[size]
+	}
+	r.Overshoot = r.TotalItems - numberOfItems
+
+	return r
+}
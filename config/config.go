@@ -3,6 +3,7 @@ package config
 import (
 	"net/url"
 	"os"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
@@ -17,6 +18,20 @@ type Configuration struct {
 	LogLevel    string `envconfig:"log_level" default:"info"`
 	FrontendURL string `envconfig:"frontend_url"`
 	EnableDBSSL bool   `envconfig:"enable_db_ssl" default:"false"`
+	// AdminToken is the bearer token required by the admin endpoints,
+	// the admin endpoints are disabled when it is empty
+	AdminToken string `envconfig:"admin_token"`
+	// PackingStrategy is the strategy used for orders that don't ask for one
+	PackingStrategy string `envconfig:"packing_strategy" default:"least_overshoot"`
+	// MaxOrderItems is the most items an order or a quote can have, packing
+	// takes the same memory for any order up to the default of 1e12 items
+	MaxOrderItems int `envconfig:"max_order_items" default:"1000000000000"`
+	// MaxPackingTable is the most totals checked to pack an order, each takes 32 bytes
+	MaxPackingTable int `envconfig:"max_packing_table" default:"2000000"`
+	// PackingTimeout is the longest time spent packing an order
+	PackingTimeout time.Duration `envconfig:"packing_timeout" default:"5s"`
+	// IdempotencyKeyTTL is how long an Idempotency-Key replays the order it created
+	IdempotencyKeyTTL time.Duration `envconfig:"idempotency_key_ttl" default:"24h"`
 }
 
 // GetConfig create a configuration object from the environment variables,
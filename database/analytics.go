@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+// buckets of the packing analytics, the period each bucket covers
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// analyticsOrders are the orders counted in the packing analytics, deleted and
+// cancelled orders were never shipped so they are left out. $1 and $2 are the
+// range of the analytics and $3 the bucket.
+const analyticsOrders = `o.deleted_at IS NULL AND o.status <> '` + models.OrderCancelled + `'
+	AND o.created_at >= $1 AND o.created_at < $2`
+
+// GetPackingAnalytics returns how efficiently the orders placed from from
+// until to were packed, in total and for each bucket of time
+func (ps *postgresService) GetPackingAnalytics(ctx context.Context, from, to time.Time,
+	bucket string,
+) (*models.PackingAnalytics, error) {
+	if bucket != BucketDay && bucket != BucketWeek && bucket != BucketMonth {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBucket, bucket)
+	}
+
+	buckets, err := ps.getPackingBuckets(ctx, from, to, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if err := ps.addPackUsage(ctx, from, to, bucket, buckets); err != nil {
+		return nil, err
+	}
+
+	analytics := &models.PackingAnalytics{From: from.UTC().Format(time.RFC3339), To: to.UTC().Format(time.RFC3339),
+		Bucket: bucket, Buckets: buckets, Totals: models.PackingStats{Packs: []models.PackUsage{}}}
+	totalPacks := map[int]int{}
+	for i := range buckets {
+		b := &buckets[i].PackingStats
+		setOvershoot(b)
+		analytics.Totals.Orders += b.Orders
+		analytics.Totals.ItemsOrdered += b.ItemsOrdered
+		analytics.Totals.ItemsShipped += b.ItemsShipped
+		for _, usage := range b.Packs {
+			totalPacks[usage.PackSize] += usage.Count
+		}
+	}
+	setOvershoot(&analytics.Totals)
+	sizes := slices.Sorted(maps.Keys(totalPacks))
+	slices.Reverse(sizes)
+	for _, size := range sizes {
+		analytics.Totals.Packs = append(analytics.Totals.Packs, models.PackUsage{PackSize: size, Count: totalPacks[size]})
+	}
+
+	return analytics, nil
+}
+
+// setOvershoot sets the overshoot of stats from the items ordered and shipped
+func setOvershoot(stats *models.PackingStats) {
+	stats.Overshoot = stats.ItemsShipped - stats.ItemsOrdered
+	if stats.ItemsOrdered > 0 {
+		percent := float64(stats.Overshoot) * 100 / float64(stats.ItemsOrdered)
+		stats.OvershootPercent = math.Round(percent*100) / 100
+	}
+}
+
+// getPackingBuckets returns the orders and items of every bucket in the range, oldest first
+func (ps *postgresService) getPackingBuckets(ctx context.Context, from, to time.Time,
+	bucket string,
+) ([]models.PackingBucket, error) {
+	query := `WITH analytics_orders AS (
+		SELECT date_trunc($3::text, o.created_at AT TIME ZONE 'UTC') AS start, o.number_of_items,
+		COALESCE((SELECT SUM(s.pack_size * s.shipping_pack_quantity) FROM order_shipping s WHERE s.order_id = o.id), 0)
+		AS items_shipped
+		FROM orders o WHERE ` + analyticsOrders + `
+	)
+	SELECT b.start, COUNT(a.start), COALESCE(SUM(a.number_of_items), 0), COALESCE(SUM(a.items_shipped), 0)
+	FROM generate_series(date_trunc($3::text, $1::timestamptz AT TIME ZONE 'UTC'),
+		$2::timestamptz AT TIME ZONE 'UTC' - interval '1 microsecond', ('1 ' || $3::text)::interval) AS b(start)
+	LEFT JOIN analytics_orders a ON a.start = b.start
+	GROUP BY b.start ORDER BY b.start`
+	rows, err := ps.db.QueryContext(ctx, query, from, to, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("error getting packing analytics from db: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []models.PackingBucket{}
+	for rows.Next() {
+		var start time.Time
+		b := models.PackingBucket{PackingStats: models.PackingStats{Packs: []models.PackUsage{}}}
+		if err := rows.Scan(&start, &b.Orders, &b.ItemsOrdered, &b.ItemsShipped); err != nil {
+			return nil, fmt.Errorf("could not get packing analytics: %w", err)
+		}
+		b.Start = start.UTC().Format(time.RFC3339)
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get packing analytics: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// addPackUsage adds the packs of each size used in each bucket to the buckets
+func (ps *postgresService) addPackUsage(ctx context.Context, from, to time.Time, bucket string,
+	buckets []models.PackingBucket,
+) error {
+	query := `SELECT date_trunc($3::text, o.created_at AT TIME ZONE 'UTC'), s.pack_size, SUM(s.shipping_pack_quantity)
+	FROM orders o JOIN order_shipping s ON s.order_id = o.id WHERE ` + analyticsOrders + `
+	GROUP BY 1, 2 ORDER BY 1, 2 DESC`
+	rows, err := ps.db.QueryContext(ctx, query, from, to, bucket)
+	if err != nil {
+		return fmt.Errorf("error getting pack usage from db: %w", err)
+	}
+	defer rows.Close()
+
+	index := make(map[string]int, len(buckets))
+	for i, b := range buckets {
+		index[b.Start] = i
+	}
+	for rows.Next() {
+		var start time.Time
+		var usage models.PackUsage
+		if err := rows.Scan(&start, &usage.PackSize, &usage.Count); err != nil {
+			return fmt.Errorf("could not get pack usage: %w", err)
+		}
+		i, ok := index[start.UTC().Format(time.RFC3339)]
+		if !ok {
+			continue
+		}
+		buckets[i].Packs = append(buckets[i].Packs, usage)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not get pack usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetOrderQuantities returns the number of orders placed from from until to
+// for each number of items, in order of the number of items
+func (ps *postgresService) GetOrderQuantities(ctx context.Context, from, to time.Time) ([]models.OrderQuantity, error) {
+	query := `SELECT o.number_of_items, COUNT(*) FROM orders o WHERE ` + analyticsOrders + `
+	GROUP BY o.number_of_items ORDER BY o.number_of_items`
+	rows, err := ps.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error getting order quantities from db: %w", err)
+	}
+	defer rows.Close()
+
+	quantities := []models.OrderQuantity{}
+	for rows.Next() {
+		var quantity models.OrderQuantity
+		if err := rows.Scan(&quantity.NumberOfItems, &quantity.Orders); err != nil {
+			return nil, fmt.Errorf("could not get order quantity: %w", err)
+		}
+		quantities = append(quantities, quantity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get order quantities: %w", err)
+	}
+
+	return quantities, nil
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/spankie/gymshark/database/models"
+)
+
+const customerColumns = `id, email, name, strategy, refused_pack_sizes, created_at, updated_at`
+
+func scanCustomer(row interface{ Scan(dest ...any) error }, customer *models.Customer) error {
+	var refused pq.Int64Array
+	err := row.Scan(&customer.ID, &customer.Email, &customer.Name, &customer.Strategy, &refused,
+		&customer.CreatedAt, &customer.UpdateAt)
+	if err != nil {
+		return err
+	}
+
+	customer.RefusedPackSizes = make([]int, len(refused))
+	for i, size := range refused {
+		customer.RefusedPackSizes[i] = int(size)
+	}
+
+	return nil
+}
+
+// CreateCustomer inserts a customer
+func (ps *postgresService) CreateCustomer(ctx context.Context, customer *models.Customer) error {
+	query := `INSERT INTO customers (id, email, name, strategy, refused_pack_sizes) VALUES (DEFAULT, $1, $2, $3, COALESCE($4::int[], '{}'))
+	RETURNING ` + customerColumns
+	row := ps.db.QueryRowContext(ctx, query, customer.Email, customer.Name, customer.Strategy,
+		pq.Array(customer.RefusedPackSizes))
+	err := scanCustomer(row, customer)
+	if isUniqueViolation(err) {
+		return ErrDuplicateEmail
+	}
+	if err != nil {
+		return fmt.Errorf("could not insert customer: %w", err)
+	}
+
+	return nil
+}
+
+// GetCustomer returns the customer with the given id
+func (ps *postgresService) GetCustomer(ctx context.Context, id int) (*models.Customer, error) {
+	query := `SELECT ` + customerColumns + ` FROM customers WHERE id = $1`
+
+	var customer models.Customer
+	err := scanCustomer(ps.db.QueryRowContext(ctx, query, id), &customer)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get customer: %w", err)
+	}
+
+	return &customer, nil
+}
+
+// GetCustomers returns every customer
+func (ps *postgresService) GetCustomers(ctx context.Context) ([]models.Customer, error) {
+	query := `SELECT ` + customerColumns + ` FROM customers ORDER BY id`
+	rows, err := ps.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error query db for customers: %w", err)
+	}
+	defer rows.Close()
+
+	customers := []models.Customer{}
+	for rows.Next() {
+		var customer models.Customer
+		if err := scanCustomer(rows, &customer); err != nil {
+			return nil, fmt.Errorf("could not get customer: %w", err)
+		}
+		customers = append(customers, customer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning columns from customer: %w", err)
+	}
+
+	return customers, nil
+}
+
+// UpdateCustomer replaces the details and packing settings of a customer
+func (ps *postgresService) UpdateCustomer(ctx context.Context, customer *models.Customer) error {
+	query := `UPDATE customers SET email = $2, name = $3, strategy = $4, refused_pack_sizes = COALESCE($5::int[], '{}'),
+	updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING ` + customerColumns
+	row := ps.db.QueryRowContext(ctx, query, customer.ID, customer.Email, customer.Name, customer.Strategy,
+		pq.Array(customer.RefusedPackSizes))
+	err := scanCustomer(row, customer)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return ErrDuplicateEmail
+	}
+	if err != nil {
+		return fmt.Errorf("could not update customer: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCustomer deletes a customer, customers with orders cannot be deleted
+func (ps *postgresService) DeleteCustomer(ctx context.Context, id int) error {
+	result, err := ps.db.ExecContext(ctx, `DELETE FROM customers WHERE id = $1`, id)
+	if isForeignKeyViolation(err) {
+		return ErrCustomerHasOrders
+	}
+	if err != nil {
+		return fmt.Errorf("could not delete customer: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not delete customer: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
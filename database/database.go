@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	// postgres driver
@@ -16,9 +17,49 @@ import (
 type Service interface {
 	Health(ctx context.Context) (string, error)
 	CreateOrder(ctx context.Context, order *models.Order, orderShipping []*models.OrderShipping) error
+	CreateOrders(ctx context.Context, orders []NewOrder) error
 	GetOrder(ctx context.Context, id int) (*models.Order, error)
 	GetAvailableShippingPacks(ctx context.Context) ([]models.ShippingPack, error)
-	GetOrdersShipping(ctx context.Context) ([]models.Order, error)
+	GetShippingPack(ctx context.Context, id int) (*models.ShippingPack, error)
+	CreateShippingPack(ctx context.Context, pack *models.ShippingPack) error
+	UpdateShippingPack(ctx context.Context, pack *models.ShippingPack) error
+	DeleteShippingPack(ctx context.Context, id int) (*models.PackCatalog, error)
+	SetShipmentFee(ctx context.Context, fee int) (*models.PackCatalog, error)
+	GetActivePackCatalog(ctx context.Context) (*models.PackCatalog, error)
+	GetPackCatalog(ctx context.Context, version int) (*models.PackCatalog, error)
+	GetPackCatalogs(ctx context.Context) ([]models.PackCatalog, error)
+	ActivatePackCatalog(ctx context.Context, version int) (*models.PackCatalog, error)
+	CreateProduct(ctx context.Context, product *models.Product, packSizes []int) error
+	GetProduct(ctx context.Context, id int) (*models.Product, error)
+	GetProducts(ctx context.Context) ([]models.Product, error)
+	SetProductPacks(ctx context.Context, productID int, packSizes []int) (*models.PackCatalog, error)
+	CreateCustomer(ctx context.Context, customer *models.Customer) error
+	GetCustomer(ctx context.Context, id int) (*models.Customer, error)
+	GetCustomers(ctx context.Context) ([]models.Customer, error)
+	UpdateCustomer(ctx context.Context, customer *models.Customer) error
+	DeleteCustomer(ctx context.Context, id int) error
+	GetOrdersShipping(ctx context.Context, filter OrdersFilter) (*OrdersPage, error)
+	ExportOrders(ctx context.Context, filter OrdersFilter) (*OrderExport, error)
+	CreateRepackJob(ctx context.Context, job *models.RepackJob) error
+	GetRepackJob(ctx context.Context, id int) (*models.RepackJob, error)
+	GetRepackJobs(ctx context.Context) ([]models.RepackJob, error)
+	ClaimRepackJob(ctx context.Context, id int, stale time.Duration) (*models.RepackJob, error)
+	FinishRepackJob(ctx context.Context, job *models.RepackJob, status string, message *string) error
+	GetRepackOrders(ctx context.Context, filter models.RepackFilter, afterID, limit int) ([]models.Order, error)
+	SaveRepackBatch(ctx context.Context, job *models.RepackJob, batch []RepackedOrder) error
+	GetRepackResults(ctx context.Context, jobID, afterID, limit int, changedOnly bool) ([]models.RepackResult, error)
+	GetPackingAnalytics(ctx context.Context, from, to time.Time, bucket string) (*models.PackingAnalytics, error)
+	GetOrderQuantities(ctx context.Context, from, to time.Time) ([]models.OrderQuantity, error)
+	DeleteOrder(ctx context.Context, id int, reason string) error
+	RestoreOrder(ctx context.Context, id int) error
+	TransitionOrder(ctx context.Context, transition *models.OrderTransition) error
+	AmendOrder(ctx context.Context, order *models.Order, orderShipping []*models.OrderShipping, version int) error
+	GetPackStock(ctx context.Context) ([]models.PackStock, error)
+	SetPackStock(ctx context.Context, stock *models.PackStock) error
+	DeletePackStock(ctx context.Context, packSize int) error
+	ReserveIdempotencyKey(ctx context.Context, key, fingerprint string, ttl time.Duration) (*models.IdempotencyKey, error)
+	SaveIdempotencyResponse(ctx context.Context, key string, statusCode int, response []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
 }
 
 type postgresService struct {
@@ -82,32 +123,91 @@ func (ps *postgresService) Health(ctx context.Context) (string, error) {
 
 // CreateOrder inserts an order into the database
 func (ps *postgresService) CreateOrder(ctx context.Context, order *models.Order, orderShipping []*models.OrderShipping) error {
+	return ps.CreateOrders(ctx, []NewOrder{{Order: order, Shipping: orderShipping}})
+}
+
+// NewOrder is an order to insert and the packs it is shipped in
+type NewOrder struct {
+	Order    *models.Order
+	Shipping []*models.OrderShipping
+}
+
+// CreateOrders inserts orders in a single transaction, none of them are
+// inserted when one of them cannot be
+func (ps *postgresService) CreateOrders(ctx context.Context, orders []NewOrder) error {
 	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return fmt.Errorf("unable to start db transaction: %w", err)
 	}
 
-	query := `INSERT INTO orders (id, number_of_items) VALUES (DEFAULT, $1) RETURNING id, number_of_items, created_at, updated_at`
-	row := tx.QueryRowContext(ctx, query, order.NumberOfItems)
-	err = row.Scan(&order.ID, &order.NumberOfItems, &order.CreatedAt, &order.UpdateAt)
+	for _, o := range orders {
+		if err := insertOrder(ctx, tx, o.Order, o.Shipping); err != nil {
+			return errors.Join(err, rollback(tx))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit db transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertOrder inserts an order, its lines and shipping, and takes its packs out of the stock
+func insertOrder(ctx context.Context, tx *sql.Tx, order *models.Order, orderShipping []*models.OrderShipping) error {
+	query := `INSERT INTO orders (id, number_of_items, catalog_version, strategy, cost, customer_id, external_reference) VALUES (DEFAULT, $1, $2, COALESCE(NULLIF($3::varchar, ''), 'least_overshoot'), $4, $5, $6)
+	RETURNING id, number_of_items, catalog_version, strategy, cost, customer_id, external_reference, status, version, created_at, updated_at`
+	row := tx.QueryRowContext(ctx, query, order.NumberOfItems, order.CatalogVersion, order.Strategy, order.Cost,
+		order.CustomerID, order.ExternalReference)
+	err := row.Scan(&order.ID, &order.NumberOfItems, &order.CatalogVersion, &order.Strategy, &order.Cost,
+		&order.CustomerID, &order.ExternalReference, &order.Status, &order.Version, &order.CreatedAt, &order.UpdateAt)
 	if err != nil {
-		return errors.Join(fmt.Errorf("could not insert order: %w", err), rollback(tx))
+		return fmt.Errorf("could not insert order: %w", err)
 	}
 
-	queryOrderShipping := `INSERT INTO order_shipping
-	(id, order_id, pack_size, shipping_pack_quantity)
-	VALUES (DEFAULT, $1, $2, $3) RETURNING id`
-	for k, v := range orderShipping {
-		row := tx.QueryRowContext(ctx, queryOrderShipping, order.ID, v.PackSize, v.ShippingPackQuantity)
-		err := row.Scan(&orderShipping[k].ID)
+	created := models.OrderTransition{OrderID: order.ID, To: order.Status}
+	if err := insertOrderTransition(ctx, tx, &created); err != nil {
+		return err
+	}
+	order.Transitions = []models.OrderTransition{created}
+
+	queryOrderLine := `INSERT INTO order_lines (id, order_id, product_id, quantity, catalog_version)
+	VALUES (DEFAULT, $1, $2, $3, $4) RETURNING id, created_at, updated_at`
+	for k, line := range order.Lines {
+		row := tx.QueryRowContext(ctx, queryOrderLine, order.ID, line.ProductID, line.Quantity, line.CatalogVersion)
+		err := row.Scan(&order.Lines[k].ID, &order.Lines[k].CreatedAt, &order.Lines[k].UpdateAt)
 		if err != nil {
-			return errors.Join(fmt.Errorf("could not insert order_shipping: %w", err), rollback(tx))
+			return fmt.Errorf("could not insert order_line: %w", err)
+		}
+		order.Lines[k].OrderID = order.ID
+
+		for i := range line.Shipping {
+			line.Shipping[i].OrderLineID = &order.Lines[k].ID
+			if err := insertOrderShipping(ctx, tx, order.ID, &line.Shipping[i]); err != nil {
+				return err
+			}
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("could not commit db transaction: %w", err)
+	for _, v := range orderShipping {
+		if err := insertOrderShipping(ctx, tx, order.ID, v); err != nil {
+			return err
+		}
+		order.Shipping = append(order.Shipping, *v)
+	}
+
+	return takePackStock(ctx, tx, orderPacksUsed(order, orderShipping))
+}
+
+func insertOrderShipping(ctx context.Context, tx *sql.Tx, orderID int, shipping *models.OrderShipping) error {
+	query := `INSERT INTO order_shipping
+	(id, order_id, order_line_id, pack_size, shipping_pack_quantity)
+	VALUES (DEFAULT, $1, $2, $3, $4) RETURNING id`
+	row := tx.QueryRowContext(ctx, query, orderID, shipping.OrderLineID, shipping.PackSize, shipping.ShippingPackQuantity)
+	if err := row.Scan(&shipping.ID); err != nil {
+		return fmt.Errorf("could not insert order_shipping: %w", err)
 	}
+	shipping.OrderID = orderID
 
 	return nil
 }
@@ -122,86 +222,100 @@ func rollback(tx *sql.Tx) error {
 }
 
 func (ps *postgresService) GetOrder(ctx context.Context, id int) (*models.Order, error) {
-	query := `SELECT id, number_of_items, created_at, updated_at FROM orders where id = $1`
+	query := `SELECT id, number_of_items, catalog_version, strategy, cost, customer_id, external_reference, status,
+	deleted_at, deleted_reason, version, created_at, updated_at FROM orders where id = $1`
 	row := ps.db.QueryRowContext(ctx, query, id)
 
 	var order models.Order
-	err := row.Scan(&order.ID, &order.NumberOfItems, &order.CreatedAt, &order.UpdateAt)
+	err := row.Scan(&order.ID, &order.NumberOfItems, &order.CatalogVersion, &order.Strategy, &order.Cost,
+		&order.CustomerID, &order.ExternalReference, &order.Status, &order.DeletedAt, &order.DeletedReason, &order.Version, &order.CreatedAt,
+		&order.UpdateAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("could not get order: %w", err)
 	}
 
+	order.Lines, err = ps.getOrderLines(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Transitions, err = ps.getOrderTransitions(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Revisions, err = ps.getOrderRevisions(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// fetch the order shipping
-	shippingQuery := `SELECT id, pack_size, shipping_pack_quantity FROM order_shipping WHERE order_id = $1 ORDER BY pack_size DESC`
+	shippingQuery := `SELECT id, order_line_id, pack_size, shipping_pack_quantity FROM order_shipping WHERE order_id = $1 ORDER BY pack_size DESC`
 	rows, err := ps.db.QueryContext(ctx, shippingQuery, order.ID)
 	if err != nil {
 		return nil, fmt.Errorf("error finding shipping details for order: %v", err)
 	}
+	defer rows.Close()
 
+	// the shipping of each line is added to the line, and the order gets the total per pack size
 	for rows.Next() {
-		shipping := models.OrderShipping{}
-		err := rows.Scan(&shipping.ID, &shipping.PackSize, &shipping.ShippingPackQuantity)
+		shipping := models.OrderShipping{OrderID: order.ID}
+		err := rows.Scan(&shipping.ID, &shipping.OrderLineID, &shipping.PackSize, &shipping.ShippingPackQuantity)
 		if err != nil {
 			return nil, fmt.Errorf("could not get order shipping information: %v", err)
 		}
-		order.Shipping = append(order.Shipping, shipping)
-	}
-
-	return &order, nil
-}
-
-func (ps *postgresService) GetAvailableShippingPacks(ctx context.Context) ([]models.ShippingPack, error) {
-	query := `SELECT id, quantity, created_at, updated_at FROM shipping_packs ORDER BY quantity DESC`
-	rows, err := ps.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("error query db for shipping packs: %w", err)
-	}
+		if shipping.OrderLineID == nil {
+			order.Shipping = append(order.Shipping, shipping)
+			continue
+		}
 
-	var packs []models.ShippingPack
-	for rows.Next() {
-		if err := rows.Err(); err != nil {
-			return nil, fmt.Errorf("error scanning columns from shipping pack: %w", err)
+		i := slices.IndexFunc(order.Lines, func(l models.OrderLine) bool { return l.ID == *shipping.OrderLineID })
+		if i < 0 {
+			return nil, fmt.Errorf("order shipping %d refers to line %d, which is not a line of order %d",
+				shipping.ID, *shipping.OrderLineID, order.ID)
 		}
-		var pack models.ShippingPack
-		err := rows.Scan(&pack.ID, &pack.Quantity, &pack.CreatedAt, &pack.UpdateAt)
-		if err != nil {
-			return nil, fmt.Errorf("could not get order: %w", err)
+		order.Lines[i].Shipping = append(order.Lines[i].Shipping, shipping)
+
+		// rows are sorted by pack size, so the total for this pack size is the last one
+		last := len(order.Shipping) - 1
+		if last < 0 || order.Shipping[last].PackSize != shipping.PackSize {
+			order.Shipping = append(order.Shipping, models.OrderShipping{OrderID: order.ID, PackSize: shipping.PackSize})
+			last++
 		}
-		packs = append(packs, pack)
+		order.Shipping[last].ShippingPackQuantity += shipping.ShippingPackQuantity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get order shipping information: %v", err)
 	}
 
-	return packs, nil
+	return &order, nil
 }
 
-func (ps *postgresService) GetOrdersShipping(ctx context.Context) ([]models.Order, error) {
-	query := `select o.id, o.number_of_items, o.created_at, s.pack_size, s.shipping_pack_quantity from orders o join order_shipping s on o.id = s.order_id ORDER BY o.created_at DESC;`
-	rows, err := ps.db.QueryContext(ctx, query)
+func (ps *postgresService) getOrderLines(ctx context.Context, orderID int) ([]models.OrderLine, error) {
+	query := `SELECT id, order_id, product_id, quantity, catalog_version, created_at, updated_at
+	FROM order_lines WHERE order_id = $1 ORDER BY id`
+	rows, err := ps.db.QueryContext(ctx, query, orderID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting order shipping from db: %v", err)
+		return nil, fmt.Errorf("error finding lines for order: %w", err)
 	}
+	defer rows.Close()
 
-	orderMap := make(map[int]models.Order)
+	var lines []models.OrderLine
 	for rows.Next() {
-		order := models.Order{}
-		s := models.OrderShipping{}
-		err := rows.Scan(&order.ID, &order.NumberOfItems, &order.CreatedAt, &s.PackSize, &s.ShippingPackQuantity)
+		var line models.OrderLine
+		err := rows.Scan(&line.ID, &line.OrderID, &line.ProductID, &line.Quantity, &line.CatalogVersion,
+			&line.CreatedAt, &line.UpdateAt)
 		if err != nil {
-			return nil, err
-		}
-		if _, ok := orderMap[order.ID]; ok {
-			temp := orderMap[order.ID]
-			temp.Shipping = append(temp.Shipping, s)
-			orderMap[order.ID] = temp
-		} else {
-			order.Shipping = append(order.Shipping, s)
-			orderMap[order.ID] = order
+			return nil, fmt.Errorf("could not get order line: %w", err)
 		}
+		lines = append(lines, line)
 	}
-
-	orders := make([]models.Order, 0, len(orderMap))
-	for _, v := range orderMap {
-		orders = append(orders, v)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get order lines: %w", err)
 	}
 
-	return orders, nil
+	return lines, nil
 }
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicatePackSize is returned when a shipping pack with the same size already exists
+	ErrDuplicatePackSize = errors.New("a shipping pack with this size already exists")
+	// ErrLastShippingPack is returned when removing the only shipping pack left,
+	// orders cannot be packed without at least one pack size
+	ErrLastShippingPack = errors.New("cannot remove the last shipping pack")
+	// ErrDuplicateSKU is returned when a product with the same sku already exists
+	ErrDuplicateSKU = errors.New("a product with this sku already exists")
+	// ErrInsufficientStock is returned when an order needs more packs of a size than are left in stock
+	ErrInsufficientStock = errors.New("not enough packs in stock")
+	// ErrStatusChanged is returned when an order is no longer in the status a transition starts from
+	ErrStatusChanged = errors.New("the order status has changed")
+	// ErrOrderDeleted is returned when changing an order that was deleted
+	ErrOrderDeleted = errors.New("the order is deleted")
+	// ErrOrderNotDeleted is returned when restoring an order that was not deleted
+	ErrOrderNotDeleted = errors.New("the order is not deleted")
+	// ErrVersionConflict is returned when an order changed since the version an edit was made from
+	ErrVersionConflict = errors.New("the order has changed since this version")
+	// ErrInvalidCursor is returned when a page cursor cannot be decoded or is for another sort order
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidSort is returned when the orders are sorted by an unknown sort order
+	ErrInvalidSort = errors.New("invalid sort order")
+	// ErrInvalidBucket is returned when analytics are grouped by an unknown period
+	ErrInvalidBucket = errors.New("invalid analytics bucket")
+	// ErrDuplicateEmail is returned when a customer with the same email already exists
+	ErrDuplicateEmail = errors.New("a customer with this email already exists")
+	// ErrRepackJobNotResumable is returned when running a repack job that is completed or already running
+	ErrRepackJobNotResumable = errors.New("the repack job is completed or already running")
+	// ErrCustomerHasOrders is returned when deleting a customer that has orders
+	ErrCustomerHasOrders = errors.New("the customer has orders")
+)
+
+// postgres error codes for constraint violations
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// isUniqueViolation reports whether err was caused by a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// isForeignKeyViolation reports whether err was caused by a foreign key constraint violation
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation
+}
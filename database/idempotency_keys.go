@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+// ReserveIdempotencyKey stores key for a request with the given fingerprint
+// until ttl has passed. It returns the stored key instead when key was
+// already used by a request that has not expired, and nil when key is reserved.
+func (ps *postgresService) ReserveIdempotencyKey(ctx context.Context, key, fingerprint string,
+	ttl time.Duration) (*models.IdempotencyKey, error) {
+	_, err := ps.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= CURRENT_TIMESTAMP`)
+	if err != nil {
+		return nil, fmt.Errorf("could not delete expired idempotency keys: %w", err)
+	}
+
+	query := `INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+	VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(secs => $3)) ON CONFLICT (key) DO NOTHING`
+	result, err := ps.db.ExecContext(ctx, query, key, fingerprint, ttl.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("could not insert idempotency key: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("could not insert idempotency key: %w", err)
+	}
+	if n == 1 {
+		return nil, nil
+	}
+
+	stored := &models.IdempotencyKey{}
+	var statusCode sql.NullInt64
+	query = `SELECT key, fingerprint, status_code, response, created_at, expires_at
+	FROM idempotency_keys WHERE key = $1`
+	err = ps.db.QueryRowContext(ctx, query, key).Scan(&stored.Key, &stored.Fingerprint, &statusCode,
+		&stored.Response, &stored.CreatedAt, &stored.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("could not get idempotency key: %w", err)
+	}
+	stored.StatusCode = int(statusCode.Int64)
+
+	return stored, nil
+}
+
+// SaveIdempotencyResponse stores the response to the request key was reserved for
+func (ps *postgresService) SaveIdempotencyResponse(ctx context.Context, key string, statusCode int,
+	response []byte) error {
+	query := `UPDATE idempotency_keys SET status_code = $2, response = $3 WHERE key = $1`
+	_, err := ps.db.ExecContext(ctx, query, key, statusCode, response)
+	if err != nil {
+		return fmt.Errorf("could not save idempotency response: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes a key that has no response yet, so the
+// request it was reserved for can be retried with it
+func (ps *postgresService) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	_, err := ps.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND response IS NULL`, key)
+	if err != nil {
+		return fmt.Errorf("could not release idempotency key: %w", err)
+	}
+
+	return nil
+}
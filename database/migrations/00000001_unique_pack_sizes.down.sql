@@ -0,0 +1,2 @@
+ALTER TABLE shipping_packs DROP CONSTRAINT IF EXISTS shipping_packs_quantity_check;
+ALTER TABLE shipping_packs DROP CONSTRAINT IF EXISTS shipping_packs_quantity_key;
@@ -0,0 +1,2 @@
+ALTER TABLE shipping_packs ADD CONSTRAINT shipping_packs_quantity_key UNIQUE (quantity);
+ALTER TABLE shipping_packs ADD CONSTRAINT shipping_packs_quantity_check CHECK (quantity > 0);
@@ -0,0 +1,8 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS catalog_version;
+
+DELETE FROM shipping_packs WHERE catalog_version <> (SELECT version FROM pack_catalogs WHERE active);
+ALTER TABLE shipping_packs DROP CONSTRAINT IF EXISTS shipping_packs_catalog_version_quantity_key;
+ALTER TABLE shipping_packs ADD CONSTRAINT shipping_packs_quantity_key UNIQUE (quantity);
+ALTER TABLE shipping_packs DROP COLUMN IF EXISTS catalog_version;
+
+DROP TABLE IF EXISTS pack_catalogs;
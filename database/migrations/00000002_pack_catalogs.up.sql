@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS pack_catalogs (
+    version SERIAL PRIMARY KEY,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- only one catalog can be active at a time
+CREATE UNIQUE INDEX IF NOT EXISTS pack_catalogs_active_idx ON pack_catalogs (active) WHERE active;
+
+-- the existing pack sizes become the first catalog version
+INSERT INTO pack_catalogs (version, active) VALUES (DEFAULT, TRUE);
+
+ALTER TABLE shipping_packs ADD COLUMN catalog_version INT REFERENCES pack_catalogs(version);
+UPDATE shipping_packs SET catalog_version = (SELECT version FROM pack_catalogs WHERE active);
+ALTER TABLE shipping_packs ALTER COLUMN catalog_version SET NOT NULL;
+ALTER TABLE shipping_packs DROP CONSTRAINT IF EXISTS shipping_packs_quantity_key;
+ALTER TABLE shipping_packs ADD CONSTRAINT shipping_packs_catalog_version_quantity_key UNIQUE (catalog_version, quantity);
+
+-- orders created before catalogs were versioned have no catalog version
+ALTER TABLE orders ADD COLUMN catalog_version INT REFERENCES pack_catalogs(version);
@@ -0,0 +1,10 @@
+ALTER TABLE order_shipping DROP COLUMN IF EXISTS order_line_id;
+DROP TABLE IF EXISTS order_lines;
+
+DELETE FROM shipping_packs WHERE catalog_version IN (SELECT version FROM pack_catalogs WHERE product_id IS NOT NULL);
+DELETE FROM pack_catalogs WHERE product_id IS NOT NULL;
+DROP INDEX IF EXISTS pack_catalogs_active_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS pack_catalogs_active_idx ON pack_catalogs (active) WHERE active;
+ALTER TABLE pack_catalogs DROP COLUMN IF EXISTS product_id;
+
+DROP TABLE IF EXISTS products;
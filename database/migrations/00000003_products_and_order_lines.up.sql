@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS products (
+    id SERIAL PRIMARY KEY,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- products can have their own catalogs, the global catalog has no product
+ALTER TABLE pack_catalogs ADD COLUMN product_id INT REFERENCES products(id) ON DELETE CASCADE;
+DROP INDEX IF EXISTS pack_catalogs_active_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS pack_catalogs_active_idx ON pack_catalogs (COALESCE(product_id, 0)) WHERE active;
+
+CREATE TABLE IF NOT EXISTS order_lines (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    catalog_version INT NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    FOREIGN KEY (catalog_version) REFERENCES pack_catalogs(version)
+);
+
+ALTER TABLE order_shipping ADD COLUMN order_line_id INT REFERENCES order_lines(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- orders created before strategies were configurable were packed with the least overshoot
+ALTER TABLE orders ADD COLUMN strategy VARCHAR(32) NOT NULL DEFAULT 'least_overshoot';
@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS cost;
+ALTER TABLE pack_catalogs DROP COLUMN IF EXISTS shipment_fee;
+ALTER TABLE shipping_packs DROP COLUMN IF EXISTS unit_cost;
@@ -0,0 +1,6 @@
+-- costs are in the minor unit of the currency, e.g. cents
+ALTER TABLE shipping_packs ADD COLUMN unit_cost INT NOT NULL DEFAULT 0 CHECK (unit_cost >= 0);
+ALTER TABLE pack_catalogs ADD COLUMN shipment_fee INT NOT NULL DEFAULT 0 CHECK (shipment_fee >= 0);
+
+-- orders created before packs had prices have no cost
+ALTER TABLE orders ADD COLUMN cost INT;
@@ -0,0 +1,9 @@
+-- stock is kept per pack size rather than per catalog pack, the same boxes
+-- are used whatever catalog version an order is packed with.
+-- pack sizes without a row have an unlimited supply
+CREATE TABLE IF NOT EXISTS pack_stock (
+    pack_size INT PRIMARY KEY CHECK (pack_size > 0),
+    quantity INT NOT NULL CHECK (quantity >= 0),
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
@@ -0,0 +1,12 @@
+ALTER TABLE orders ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'packed', 'shipped', 'delivered', 'cancelled'));
+
+-- every status an order moved to, orders created before statuses have no history
+CREATE TABLE IF NOT EXISTS order_transitions (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(16),
+    to_status VARCHAR(16) NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS order_transitions_order_id_idx ON order_transitions (order_id);
@@ -0,0 +1,2 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS deleted_reason;
+ALTER TABLE orders DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+-- deleted orders are kept so they can be restored
+ALTER TABLE orders ADD COLUMN deleted_at timestamp with time zone;
+ALTER TABLE orders ADD COLUMN deleted_reason TEXT;
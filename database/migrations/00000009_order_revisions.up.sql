@@ -0,0 +1,17 @@
+-- the version of an order goes up with every change, edits must name the
+-- version they were made from
+ALTER TABLE orders ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- the packing an order had before it was amended
+CREATE TABLE IF NOT EXISTS order_revisions (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    number_of_items INT NOT NULL,
+    catalog_version INT,
+    strategy VARCHAR(32) NOT NULL,
+    cost INT,
+    shipping JSONB NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (order_id, version)
+);
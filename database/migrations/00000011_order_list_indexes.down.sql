@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS order_shipping_pack_size_idx;
+DROP INDEX IF EXISTS order_shipping_order_id_idx;
+DROP INDEX IF EXISTS orders_status_idx;
+DROP INDEX IF EXISTS orders_number_of_items_id_idx;
+DROP INDEX IF EXISTS orders_created_at_id_idx;
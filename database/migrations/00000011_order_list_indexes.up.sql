@@ -0,0 +1,7 @@
+-- the orders list is sorted by creation time or number of items, ties by id,
+-- and filtered by status and the pack sizes shipped
+CREATE INDEX IF NOT EXISTS orders_created_at_id_idx ON orders (created_at, id);
+CREATE INDEX IF NOT EXISTS orders_number_of_items_id_idx ON orders (number_of_items, id);
+CREATE INDEX IF NOT EXISTS orders_status_idx ON orders (status);
+CREATE INDEX IF NOT EXISTS order_shipping_order_id_idx ON order_shipping (order_id);
+CREATE INDEX IF NOT EXISTS order_shipping_pack_size_idx ON order_shipping (pack_size, order_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS orders_customer_id_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_id;
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,16 @@
+-- customers own orders and can override how their orders are packed, a null
+-- strategy uses the default strategy
+CREATE TABLE IF NOT EXISTS customers (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    strategy VARCHAR(32),
+    refused_pack_sizes INT[] NOT NULL DEFAULT '{}',
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- customers with orders cannot be deleted, their order history is kept
+ALTER TABLE orders ADD COLUMN customer_id INT REFERENCES customers(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS orders_customer_id_idx ON orders (customer_id);
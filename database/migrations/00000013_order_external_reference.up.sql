@@ -0,0 +1,2 @@
+-- the customer's own reference for an order, e.g. a purchase order number from an import
+ALTER TABLE orders ADD COLUMN external_reference VARCHAR(255);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS repack_results;
+DROP TABLE IF EXISTS repack_jobs;
@@ -0,0 +1,39 @@
+-- a repack job packs the orders matching filter again with the packs of a
+-- catalog. Orders are processed in id order, last_order_id is the last order
+-- processed so a stopped job resumes after it. A running job updates
+-- updated_at after every batch, a job not updated for a while has stopped.
+-- overshoot_change and packs_change add up the change of every order repacked.
+CREATE TABLE IF NOT EXISTS repack_jobs (
+    id SERIAL PRIMARY KEY,
+    catalog_version INT NOT NULL REFERENCES pack_catalogs(version),
+    mode VARCHAR(16) NOT NULL,
+    filter JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    last_order_id INT NOT NULL DEFAULT 0,
+    total INT NOT NULL,
+    processed INT NOT NULL DEFAULT 0,
+    changed INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    overshoot_change INT NOT NULL DEFAULT 0,
+    packs_change INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- the packing of an order before and after it was packed by a repack job
+CREATE TABLE IF NOT EXISTS repack_results (
+    job_id INT NOT NULL REFERENCES repack_jobs(id) ON DELETE CASCADE,
+    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    old_shipping JSONB NOT NULL,
+    new_shipping JSONB,
+    old_overshoot INT NOT NULL,
+    new_overshoot INT,
+    old_packs INT NOT NULL,
+    new_packs INT,
+    changed BOOLEAN NOT NULL DEFAULT FALSE,
+    applied BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (job_id, order_id)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE repack_results ALTER COLUMN old_packs TYPE INT, ALTER COLUMN new_packs TYPE INT;
+ALTER TABLE repack_jobs ALTER COLUMN packs_change TYPE INT, ALTER COLUMN overshoot_change TYPE INT;
+ALTER TABLE order_revisions ALTER COLUMN number_of_items TYPE INT, ALTER COLUMN cost TYPE INT;
+ALTER TABLE order_shipping ALTER COLUMN shipping_pack_quantity TYPE INT;
+ALTER TABLE order_lines ALTER COLUMN quantity TYPE INT;
+ALTER TABLE orders ALTER COLUMN number_of_items TYPE INT, ALTER COLUMN cost TYPE INT;
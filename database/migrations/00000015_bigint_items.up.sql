@@ -0,0 +1,7 @@
+-- orders can have more items, packs and cost than an INT holds
+ALTER TABLE orders ALTER COLUMN number_of_items TYPE BIGINT, ALTER COLUMN cost TYPE BIGINT;
+ALTER TABLE order_lines ALTER COLUMN quantity TYPE BIGINT;
+ALTER TABLE order_shipping ALTER COLUMN shipping_pack_quantity TYPE BIGINT;
+ALTER TABLE order_revisions ALTER COLUMN number_of_items TYPE BIGINT, ALTER COLUMN cost TYPE BIGINT;
+ALTER TABLE repack_jobs ALTER COLUMN packs_change TYPE BIGINT, ALTER COLUMN overshoot_change TYPE BIGINT;
+ALTER TABLE repack_results ALTER COLUMN old_packs TYPE BIGINT, ALTER COLUMN new_packs TYPE BIGINT;
@@ -0,0 +1,44 @@
+package models
+
+// PackingAnalytics is how efficiently orders were packed between From and To,
+// in total and for each bucket of time. Buckets start at the beginning of a
+// day, week or month in UTC, there is a bucket for every period in the range
+// even when no order was placed in it.
+type PackingAnalytics struct {
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Bucket  string          `json:"bucket"`
+	Totals  PackingStats    `json:"totals"`
+	Buckets []PackingBucket `json:"buckets"`
+}
+
+// PackingStats is how efficiently a set of orders was packed. Overshoot is the
+// number of items shipped beyond the items ordered, the packaging wasted, and
+// OvershootPercent is the overshoot as a percentage of the items ordered.
+// Packs is the number of packs of each size used, largest first.
+type PackingStats struct {
+	Orders           int         `json:"orders"`
+	ItemsOrdered     int         `json:"items_ordered"`
+	ItemsShipped     int         `json:"items_shipped"`
+	Overshoot        int         `json:"overshoot"`
+	OvershootPercent float64     `json:"overshoot_percent"`
+	Packs            []PackUsage `json:"packs"`
+}
+
+// PackingBucket is how efficiently the orders placed in the bucket starting at Start were packed
+type PackingBucket struct {
+	Start string `json:"start"`
+	PackingStats
+}
+
+// PackUsage is the number of packs of a size used
+type PackUsage struct {
+	PackSize int `json:"pack_size"`
+	Count    int `json:"count"`
+}
+
+// OrderQuantity is the number of orders placed for NumberOfItems items
+type OrderQuantity struct {
+	NumberOfItems int `json:"number_of_items"`
+	Orders        int `json:"orders"`
+}
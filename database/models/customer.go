@@ -0,0 +1,14 @@
+package models
+
+// Customer is who orders are placed for. Strategy is the packing strategy of
+// the customer's orders, it is nil when they use the default strategy.
+// RefusedPackSizes are the pack sizes never used to ship the customer's orders.
+type Customer struct {
+	ID               int     `json:"id"`
+	Email            string  `json:"email"`
+	Name             string  `json:"name"`
+	Strategy         *string `json:"strategy"`
+	RefusedPackSizes []int   `json:"refused_pack_sizes"`
+	CreatedAt        string  `json:"created_at"`
+	UpdateAt         string  `json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// IdempotencyKey is a key a client sent with a request and the response it
+// got. Response is nil while the request is being handled.
+type IdempotencyKey struct {
+	Key         string `json:"key"`
+	Fingerprint string `json:"fingerprint"`
+	StatusCode  int    `json:"status_code"`
+	Response    []byte `json:"-"`
+	CreatedAt   string `json:"created_at"`
+	ExpiresAt   string `json:"expires_at"`
+}
@@ -1,9 +1,83 @@
 package models
 
+// Order is a customer order and the shipping packs used to fulfil it.
+// CatalogVersion is the pack catalog the order was packed against, it is nil
+// for orders created before catalogs were versioned.
+// Strategy is the name of the packing strategy the order was packed with.
+// Cost is the price of the packs and the shipment fee, it is nil for orders
+// created before packs had prices.
+// Orders with several products have a line per product, each line is packed
+// on its own and Shipping holds the total packs of all the lines.
+// CustomerID is the customer the order was placed for, it is nil for
+// anonymous orders. ExternalReference is the customer's own reference for
+// the order, e.g. their purchase order number.
+// Status is where the order is in its lifecycle, Transitions are the statuses
+// it moved to, oldest first.
+// DeletedAt is set when the order was deleted, deleted orders can be restored.
+// Version goes up with every change to the order, Revisions are the packings
+// the order had before it was amended, oldest first.
 type Order struct {
-	ID            int             `json:"id"`
-	NumberOfItems int             `json:"number_of_items"`
-	CreatedAt     string          `json:"created_at"`
-	UpdateAt      string          `json:"updated_at"`
-	Shipping      []OrderShipping `json:"shipping"`
+	ID                int               `json:"id"`
+	NumberOfItems     int               `json:"number_of_items"`
+	CatalogVersion    *int              `json:"catalog_version"`
+	Strategy          string            `json:"strategy"`
+	Cost              *int              `json:"cost"`
+	CustomerID        *int              `json:"customer_id"`
+	ExternalReference *string           `json:"external_reference,omitempty"`
+	Status            string            `json:"status"`
+	DeletedAt         *string           `json:"deleted_at,omitempty"`
+	DeletedReason     *string           `json:"deleted_reason,omitempty"`
+	Version           int               `json:"version"`
+	CreatedAt         string            `json:"created_at"`
+	UpdateAt          string            `json:"updated_at"`
+	Lines             []OrderLine       `json:"lines,omitempty"`
+	Shipping          []OrderShipping   `json:"shipping"`
+	Transitions       []OrderTransition `json:"transitions,omitempty"`
+	Revisions         []OrderRevision   `json:"revisions,omitempty"`
+}
+
+// OrderRevision is the packing an order had at Version, before it was amended.
+// Shipping holds the total packs of each size.
+type OrderRevision struct {
+	ID             int             `json:"id"`
+	OrderID        int             `json:"order_id"`
+	Version        int             `json:"version"`
+	NumberOfItems  int             `json:"number_of_items"`
+	CatalogVersion *int            `json:"catalog_version"`
+	Strategy       string          `json:"strategy"`
+	Cost           *int            `json:"cost"`
+	Shipping       []OrderShipping `json:"shipping"`
+	CreatedAt      string          `json:"created_at"`
+}
+
+// statuses of an order
+const (
+	OrderPending   = "pending"
+	OrderPacked    = "packed"
+	OrderShipped   = "shipped"
+	OrderDelivered = "delivered"
+	OrderCancelled = "cancelled"
+)
+
+// OrderTransition is an order moving from one status to another, From is nil
+// when the order was created
+type OrderTransition struct {
+	ID        int     `json:"id"`
+	OrderID   int     `json:"order_id"`
+	From      *string `json:"from"`
+	To        string  `json:"to"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// OrderLine is the quantity of a product in an order, packed against the
+// product catalog in CatalogVersion
+type OrderLine struct {
+	ID             int             `json:"id"`
+	OrderID        int             `json:"order_id"`
+	ProductID      int             `json:"product_id"`
+	Quantity       int             `json:"quantity"`
+	CatalogVersion int             `json:"catalog_version"`
+	CreatedAt      string          `json:"created_at"`
+	UpdateAt       string          `json:"updated_at"`
+	Shipping       []OrderShipping `json:"shipping"`
 }
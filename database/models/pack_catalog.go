@@ -0,0 +1,15 @@
+package models
+
+// PackCatalog is an immutable set of shipping packs, every change to the
+// shipping packs creates a new catalog version. Catalogs with a ProductID
+// only apply to that product, only one catalog per product is active.
+// ShipmentFee is a fixed price added to every shipment packed with the catalog.
+type PackCatalog struct {
+	Version     int            `json:"version"`
+	ProductID   *int           `json:"product_id"`
+	Active      bool           `json:"active"`
+	ShipmentFee int            `json:"shipment_fee"`
+	Packs       []ShippingPack `json:"packs"`
+	CreatedAt   string         `json:"created_at"`
+	UpdateAt    string         `json:"updated_at"`
+}
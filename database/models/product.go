@@ -0,0 +1,12 @@
+package models
+
+// Product is an item customers can order. Catalog is the active pack catalog
+// of the product, it is nil when the product is packed with the global catalog.
+type Product struct {
+	ID        int          `json:"id"`
+	SKU       string       `json:"sku"`
+	Name      string       `json:"name"`
+	Catalog   *PackCatalog `json:"catalog"`
+	CreatedAt string       `json:"created_at"`
+	UpdateAt  string       `json:"updated_at"`
+}
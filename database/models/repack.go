@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// modes of a repack job
+const (
+	// RepackWhatIf reports how orders would be packed without changing them
+	RepackWhatIf = "what_if"
+	// RepackApply packs the orders again and replaces their shipping
+	RepackApply = "apply"
+)
+
+// statuses of a repack job
+const (
+	RepackPending   = "pending"
+	RepackRunning   = "running"
+	RepackCompleted = "completed"
+	RepackFailed    = "failed"
+)
+
+// RepackJob packs the orders selected by Filter again with the packs of the
+// catalog at CatalogVersion. Orders are processed in id order, LastOrderID is
+// the last order processed, a stopped job resumes after it. Total is the
+// number of orders selected when the job was created. OvershootChange and
+// PacksChange add up how the overshoot and number of packs of the orders
+// repacked changed, they are negative when the catalog packs orders better.
+type RepackJob struct {
+	ID              int          `json:"id"`
+	CatalogVersion  int          `json:"catalog_version"`
+	Mode            string       `json:"mode"`
+	Filter          RepackFilter `json:"filter"`
+	Status          string       `json:"status"`
+	LastOrderID     int          `json:"last_order_id"`
+	Total           int          `json:"total"`
+	Processed       int          `json:"processed"`
+	Changed         int          `json:"changed"`
+	Failed          int          `json:"failed"`
+	OvershootChange int          `json:"overshoot_change"`
+	PacksChange     int          `json:"packs_change"`
+	Error           *string      `json:"error,omitempty"`
+	CreatedAt       string       `json:"created_at"`
+	UpdateAt        string       `json:"updated_at"`
+}
+
+// RepackFilter selects the orders of a repack job, zero fields don't filter.
+// Deleted orders and orders with lines are never repacked.
+type RepackFilter struct {
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+	MinItems    *int       `json:"min_items,omitempty"`
+	MaxItems    *int       `json:"max_items,omitempty"`
+	PackSize    *int       `json:"pack_size,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	CustomerID  *int       `json:"customer_id,omitempty"`
+}
+
+// RepackResult is the packing of an order before and after a repack job. The
+// overshoot is the items shipped beyond the items ordered and packs the
+// number of packs shipped. The new packing is nil when the order could not be
+// packed, Error says why. Applied is set when the new packing replaced the old one.
+type RepackResult struct {
+	JobID        int             `json:"job_id"`
+	OrderID      int             `json:"order_id"`
+	OldShipping  []OrderShipping `json:"old_shipping"`
+	NewShipping  []OrderShipping `json:"new_shipping"`
+	OldOvershoot int             `json:"old_overshoot"`
+	NewOvershoot *int            `json:"new_overshoot"`
+	OldPacks     int             `json:"old_packs"`
+	NewPacks     *int            `json:"new_packs"`
+	Changed      bool            `json:"changed"`
+	Applied      bool            `json:"applied"`
+	Error        *string         `json:"error,omitempty"`
+	CreatedAt    string          `json:"created_at"`
+}
@@ -1,7 +1,21 @@
 package models
 
+// ShippingPack is a pack size of a catalog, UnitCost is the price of one
+// pack in the minor unit of the currency. Stock is the number of packs of
+// this size left, it is nil when the stock of the size is not tracked.
 type ShippingPack struct {
-	ID        int    `json:"id"`
+	ID             int    `json:"id"`
+	CatalogVersion int    `json:"catalog_version"`
+	Quantity       int    `json:"quantity"`
+	UnitCost       int    `json:"unit_cost"`
+	Stock          *int   `json:"stock"`
+	CreatedAt      string `json:"created_at"`
+	UpdateAt       string `json:"updated_at"`
+}
+
+// PackStock is the number of packs of a size available to ship orders
+type PackStock struct {
+	PackSize  int    `json:"pack_size"`
 	Quantity  int    `json:"quantity"`
 	CreatedAt string `json:"created_at"`
 	UpdateAt  string `json:"updated_at"`
@@ -10,6 +24,7 @@ type ShippingPack struct {
 type OrderShipping struct {
 	ID                   int    `json:"id"`
 	OrderID              int    `json:"order_id"`
+	OrderLineID          *int   `json:"order_line_id"`
 	PackSize             int    `json:"pack_size"`
 	ShippingPackQuantity int    `json:"shipping_pack_quantity"`
 	CreatedAt            string `json:"created_at"`
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+// stockedStatuses are the statuses of the orders that hold packs from the
+// stock, the packs of a shipped order are gone and those of a cancelled order
+// are back in stock
+var stockedStatuses = []string{models.OrderPending, models.OrderPacked}
+
+// DeleteOrder soft deletes an order with the reason it was deleted, the
+// order and its shipping are kept so it can be restored. The packs of an
+// order that was not shipped or cancelled go back in stock, as they do when
+// it is cancelled.
+func (ps *postgresService) DeleteOrder(ctx context.Context, id int, reason string) error {
+	query := `UPDATE orders SET deleted_at = CURRENT_TIMESTAMP, deleted_reason = $2, version = version + 1,
+	updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL RETURNING status`
+	return ps.setOrderDeleted(ctx, query, ErrOrderDeleted, returnPackStock, id, reason)
+}
+
+// RestoreOrder brings back a deleted order. An order that was not shipped or
+// cancelled takes its packs from the stock again, it returns
+// ErrInsufficientStock when a size has run out since it was deleted.
+func (ps *postgresService) RestoreOrder(ctx context.Context, id int) error {
+	query := `UPDATE orders SET deleted_at = NULL, deleted_reason = NULL, version = version + 1,
+	updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NOT NULL RETURNING status`
+	return ps.setOrderDeleted(ctx, query, ErrOrderNotDeleted, retakePackStock, id)
+}
+
+// setOrderDeleted runs a query that deletes or restores the order with id,
+// the first argument, and returns its status, then moves the packs of the
+// order with stock when it holds packs from the stock. When no order is
+// changed it returns ErrNotFound if the order does not exist, or unchanged if
+// it already was deleted or restored.
+func (ps *postgresService) setOrderDeleted(ctx context.Context, query string, unchanged error,
+	stock func(ctx context.Context, tx *sql.Tx, orderID int) error, id int, args ...any) error {
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to start db transaction: %w", err)
+	}
+
+	var status string
+	err = tx.QueryRowContext(ctx, query, append([]any{id}, args...)...).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.Join(orderDeletedUnchanged(ctx, tx, unchanged, id), rollback(tx))
+	}
+	if err != nil {
+		return errors.Join(fmt.Errorf("could not update order: %w", err), rollback(tx))
+	}
+
+	if slices.Contains(stockedStatuses, status) {
+		if err := stock(ctx, tx, id); err != nil {
+			return errors.Join(err, rollback(tx))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit db transaction: %w", err)
+	}
+
+	return nil
+}
+
+// orderDeletedUnchanged returns ErrNotFound when the order with id does not
+// exist, or unchanged when it does
+func orderDeletedUnchanged(ctx context.Context, tx *sql.Tx, unchanged error, id int) error {
+	var found int
+	err := tx.QueryRowContext(ctx, `SELECT id FROM orders WHERE id = $1`, id).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("could not get order: %w", err)
+	}
+
+	return unchanged
+}
+
+// retakePackStock takes the packs used by an order from the stock again, for
+// the pack sizes whose stock is tracked
+func retakePackStock(ctx context.Context, tx *sql.Tx, orderID int) error {
+	used, err := orderPacksShipped(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	return takePackStock(ctx, tx, used)
+}
+
+// orderPacksShipped returns the number of packs of each size the order with orderID ships
+func orderPacksShipped(ctx context.Context, tx *sql.Tx, orderID int) (map[int]int, error) {
+	query := `SELECT pack_size, SUM(shipping_pack_quantity) FROM order_shipping WHERE order_id = $1
+	GROUP BY pack_size`
+	rows, err := tx.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding shipping details for order: %w", err)
+	}
+	defer rows.Close()
+
+	used := make(map[int]int)
+	for rows.Next() {
+		var size, quantity int
+		if err := rows.Scan(&size, &quantity); err != nil {
+			return nil, fmt.Errorf("could not get order shipping information: %w", err)
+		}
+		used[size] = quantity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get order shipping information: %w", err)
+	}
+
+	return used, nil
+}
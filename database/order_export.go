@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+// exportBatchSize is the number of orders fetched from the export cursor at a time
+const exportBatchSize = 500
+
+// orderShippingJSON is the shipping of the order with id o.id as a json
+// array, summed per pack size, largest pack first
+const orderShippingJSON = `COALESCE((SELECT json_agg(json_build_object('pack_size', s.pack_size,
+	'shipping_pack_quantity', s.quantity) ORDER BY s.pack_size DESC)
+	FROM (SELECT pack_size, SUM(shipping_pack_quantity) AS quantity FROM order_shipping
+		WHERE order_id = o.id GROUP BY pack_size) s), '[]')`
+
+// OrderExport reads the orders of an export from a database cursor, so only
+// a batch of orders is held in memory at a time. It must be closed.
+type OrderExport struct {
+	tx         *sql.Tx
+	conditions []string
+	args       queryArgs
+	batch      []models.Order
+	done       bool
+}
+
+// ExportOrders opens a cursor over the orders selected by filter, in its sort
+// order. The limit and cursor of the filter are not used, every order is exported.
+func (ps *postgresService) ExportOrders(ctx context.Context, filter OrdersFilter) (*OrderExport, error) {
+	if filter.Sort == "" {
+		filter.Sort = SortCreatedAtDesc
+	}
+	sort, ok := orderSorts[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSort, filter.Sort)
+	}
+	direction := "ASC"
+	if sort.desc {
+		direction = "DESC"
+	}
+
+	var args queryArgs
+	conditions := orderConditions(filter, &args)
+	query := fmt.Sprintf(`DECLARE order_export NO SCROLL CURSOR FOR
+	SELECT o.id, o.number_of_items, o.catalog_version, o.strategy, o.cost, o.customer_id, o.external_reference,
+	o.status, o.deleted_at, o.deleted_reason, o.version, o.created_at, %s
+	FROM orders o WHERE %s ORDER BY %s %s, o.id %s`,
+		orderShippingJSON, strings.Join(conditions, " AND "), sort.column, direction, direction)
+
+	// the cursor only lives as long as the transaction, and the transaction
+	// reads one snapshot so PackSizes sees the same orders as the cursor
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to start db transaction: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, errors.Join(fmt.Errorf("could not open order export cursor: %w", err), rollback(tx))
+	}
+
+	return &OrderExport{tx: tx, conditions: conditions, args: args}, nil
+}
+
+// Next returns the next order of the export, and io.EOF after the last one
+func (e *OrderExport) Next(ctx context.Context) (*models.Order, error) {
+	if len(e.batch) == 0 && !e.done {
+		if err := e.fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if len(e.batch) == 0 {
+		return nil, io.EOF
+	}
+
+	order := e.batch[0]
+	e.batch = e.batch[1:]
+	return &order, nil
+}
+
+// fetch reads the next batch of orders from the cursor
+func (e *OrderExport) fetch(ctx context.Context) error {
+	rows, err := e.tx.QueryContext(ctx, fmt.Sprintf(`FETCH %d FROM order_export`, exportBatchSize))
+	if err != nil {
+		return fmt.Errorf("could not fetch exported orders: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order := models.Order{}
+		var shipping []byte
+		err := rows.Scan(&order.ID, &order.NumberOfItems, &order.CatalogVersion, &order.Strategy, &order.Cost,
+			&order.CustomerID, &order.ExternalReference, &order.Status, &order.DeletedAt, &order.DeletedReason,
+			&order.Version, &order.CreatedAt, &shipping)
+		if err != nil {
+			return fmt.Errorf("could not get exported order: %w", err)
+		}
+		if err := json.Unmarshal(shipping, &order.Shipping); err != nil {
+			return fmt.Errorf("could not decode exported order shipping: %w", err)
+		}
+		e.batch = append(e.batch, order)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not fetch exported orders: %w", err)
+	}
+	e.done = len(e.batch) < exportBatchSize
+
+	return nil
+}
+
+// Close closes the cursor of the export
+func (e *OrderExport) Close() error {
+	// the export only reads, so ending it with a rollback loses nothing
+	if err := e.tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		return fmt.Errorf("could not close order export: %w", err)
+	}
+
+	return nil
+}
+
+// PackSizes returns the pack sizes the orders of the export are shipped in,
+// largest first. It reads the same snapshot as the cursor, so every order
+// Next returns is shipped in these sizes.
+func (e *OrderExport) PackSizes(ctx context.Context) ([]int, error) {
+	query := fmt.Sprintf(`SELECT DISTINCT s.pack_size FROM order_shipping s JOIN orders o ON o.id = s.order_id
+	WHERE %s ORDER BY s.pack_size DESC`, strings.Join(e.conditions, " AND "))
+	rows, err := e.tx.QueryContext(ctx, query, e.args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting exported pack sizes: %w", err)
+	}
+	defer rows.Close()
+
+	var sizes []int
+	for rows.Next() {
+		var size int
+		if err := rows.Scan(&size); err != nil {
+			return nil, fmt.Errorf("could not get exported pack size: %w", err)
+		}
+		sizes = append(sizes, size)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get exported pack sizes: %w", err)
+	}
+
+	return sizes, nil
+}
@@ -0,0 +1,271 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// sort orders of the orders list, a leading - sorts in descending order
+const (
+	SortCreatedAt          = "created_at"
+	SortCreatedAtDesc      = "-created_at"
+	SortNumberOfItems      = "number_of_items"
+	SortNumberOfItemsDesc  = "-number_of_items"
+	DefaultOrdersPageLimit = 50
+)
+
+// OrdersFilter selects the orders listed, deleted orders are left out unless
+// IncludeDeleted is set. Zero fields don't filter. The orders are sorted by
+// Sort, newest first by default, and Cursor is the NextCursor of the previous page.
+type OrdersFilter struct {
+	IncludeDeleted bool
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	MinItems       *int
+	MaxItems       *int
+	PackSize       *int
+	Status         string
+	CustomerID     *int
+	Sort           string
+	Limit          int
+	Cursor         string
+}
+
+// OrdersPage is a page of orders, NextCursor is empty on the last page
+type OrdersPage struct {
+	Orders     []models.Order
+	NextCursor string
+}
+
+// orderSort is a sort order of the orders list, ties are sorted by id in the same direction
+type orderSort struct {
+	column string
+	// cast is the type the cursor value is compared as
+	cast string
+	// parse reads the cursor value as a value of the column
+	parse func(value string) (any, error)
+	desc  bool
+}
+
+var orderSorts = map[string]orderSort{
+	SortCreatedAt:         {column: "o.created_at", cast: "timestamptz", parse: parseCursorTime},
+	SortCreatedAtDesc:     {column: "o.created_at", cast: "timestamptz", parse: parseCursorTime, desc: true},
+	SortNumberOfItems:     {column: "o.number_of_items", cast: "bigint", parse: parseCursorInt},
+	SortNumberOfItemsDesc: {column: "o.number_of_items", cast: "bigint", parse: parseCursorInt, desc: true},
+}
+
+func parseCursorTime(value string) (any, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+func parseCursorInt(value string) (any, error) {
+	return strconv.Atoi(value)
+}
+
+// orderCursor is the position of the last order of a page in its sort order
+type orderCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func encodeCursor(cursor orderCursor) string {
+	// a struct of strings and ints always encodes
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*orderCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	cursor := &orderCursor{}
+	if err := json.Unmarshal(b, cursor); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return cursor, nil
+}
+
+// queryArgs are the arguments of a query built from optional conditions
+type queryArgs []any
+
+// add adds an argument and returns its placeholder
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// GetOrdersShipping returns a page of the orders selected by filter with the
+// shipping of each, summed per pack size for orders with several lines
+func (ps *postgresService) GetOrdersShipping(ctx context.Context, filter OrdersFilter) (*OrdersPage, error) {
+	if filter.Sort == "" {
+		filter.Sort = SortCreatedAtDesc
+	}
+	sort, ok := orderSorts[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSort, filter.Sort)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultOrdersPageLimit
+	}
+
+	var args queryArgs
+	conditions := orderConditions(filter, &args)
+	if filter.Cursor != "" {
+		condition, err := cursorCondition(filter, sort, &args)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	direction := "ASC"
+	if sort.desc {
+		direction = "DESC"
+	}
+	// one more order than the limit is read to know whether there is a next page
+	query := fmt.Sprintf(`SELECT o.id, o.number_of_items, o.catalog_version, o.strategy, o.cost, o.customer_id,
+	o.external_reference, o.status, o.deleted_at, o.deleted_reason, o.version, o.created_at FROM orders o WHERE %s
+	ORDER BY %s %s, o.id %s LIMIT %s`,
+		strings.Join(conditions, " AND "), sort.column, direction, direction, args.add(filter.Limit+1))
+	rows, err := ps.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting orders from db: %w", err)
+	}
+	defer rows.Close()
+
+	page := &OrdersPage{Orders: []models.Order{}}
+	for rows.Next() {
+		order := models.Order{}
+		err := rows.Scan(&order.ID, &order.NumberOfItems, &order.CatalogVersion, &order.Strategy, &order.Cost,
+			&order.CustomerID, &order.ExternalReference, &order.Status, &order.DeletedAt, &order.DeletedReason, &order.Version, &order.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("could not get order: %w", err)
+		}
+		page.Orders = append(page.Orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get orders: %w", err)
+	}
+
+	if len(page.Orders) > filter.Limit {
+		page.Orders = page.Orders[:filter.Limit]
+		page.NextCursor = nextCursor(filter.Sort, sort, page.Orders[len(page.Orders)-1])
+	}
+
+	if err := ps.addOrdersShipping(ctx, page.Orders); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// orderConditions returns the conditions orders must meet to be selected by filter
+func orderConditions(filter OrdersFilter, args *queryArgs) []string {
+	conditions := []string{"(" + args.add(filter.IncludeDeleted) + " OR o.deleted_at IS NULL)"}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "o.created_at >= "+args.add(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "o.created_at < "+args.add(*filter.CreatedTo))
+	}
+	if filter.MinItems != nil {
+		conditions = append(conditions, "o.number_of_items >= "+args.add(*filter.MinItems))
+	}
+	if filter.MaxItems != nil {
+		conditions = append(conditions, "o.number_of_items <= "+args.add(*filter.MaxItems))
+	}
+	if filter.PackSize != nil {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM order_shipping s WHERE s.order_id = o.id AND s.pack_size = "+
+			args.add(*filter.PackSize)+")")
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "o.status = "+args.add(filter.Status))
+	}
+	if filter.CustomerID != nil {
+		conditions = append(conditions, "o.customer_id = "+args.add(*filter.CustomerID))
+	}
+
+	return conditions
+}
+
+// cursorCondition selects the orders after the cursor of the filter in the sort order
+func cursorCondition(filter OrdersFilter, sort orderSort, args *queryArgs) (string, error) {
+	cursor, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return "", err
+	}
+	if cursor.Sort != filter.Sort {
+		return "", fmt.Errorf("%w: the cursor is for sort %s", ErrInvalidCursor, cursor.Sort)
+	}
+
+	value, err := sort.parse(cursor.Value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	after := ">"
+	if sort.desc {
+		after = "<"
+	}
+
+	return fmt.Sprintf("(%s, o.id) %s (%s::%s, %s)",
+		sort.column, after, args.add(value), sort.cast, args.add(cursor.ID)), nil
+}
+
+// nextCursor returns the cursor of the page after the last order
+func nextCursor(name string, sort orderSort, last models.Order) string {
+	value := last.CreatedAt
+	if sort.column == orderSorts[SortNumberOfItems].column {
+		value = fmt.Sprint(last.NumberOfItems)
+	}
+
+	return encodeCursor(orderCursor{Sort: name, Value: value, ID: last.ID})
+}
+
+// addOrdersShipping loads the shipping of orders, summed per pack size, largest pack first
+func (ps *postgresService) addOrdersShipping(ctx context.Context, orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(orders))
+	index := make(map[int]int, len(orders))
+	for i, order := range orders {
+		ids[i] = int64(order.ID)
+		index[order.ID] = i
+	}
+
+	query := `SELECT order_id, pack_size, SUM(shipping_pack_quantity) FROM order_shipping
+	WHERE order_id = ANY($1) GROUP BY order_id, pack_size ORDER BY order_id, pack_size DESC`
+	rows, err := ps.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error getting order shipping from db: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s := models.OrderShipping{}
+		if err := rows.Scan(&s.OrderID, &s.PackSize, &s.ShippingPackQuantity); err != nil {
+			return fmt.Errorf("could not get order shipping: %w", err)
+		}
+		order := &orders[index[s.OrderID]]
+		order.Shipping = append(order.Shipping, s)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not get order shipping: %w", err)
+	}
+
+	return nil
+}
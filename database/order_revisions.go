@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+// AmendOrder replaces the number of items, packing and cost of an order and
+// keeps its previous packing as a revision. It returns ErrVersionConflict when
+// the order is not at version anymore. The packs of the previous packing go
+// back in stock and the packs of the new one are taken from it.
+func (ps *postgresService) AmendOrder(ctx context.Context, order *models.Order, orderShipping []*models.OrderShipping,
+	version int) error {
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to start db transaction: %w", err)
+	}
+
+	if err := amendOrder(ctx, tx, order, orderShipping, version); err != nil {
+		return errors.Join(err, rollback(tx))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit db transaction: %w", err)
+	}
+
+	return nil
+}
+
+// amendOrder amends an order in the transaction tx, see AmendOrder
+func amendOrder(ctx context.Context, tx *sql.Tx, order *models.Order, orderShipping []*models.OrderShipping,
+	version int) error {
+	// the order row stays locked until the end of the transaction, so
+	// concurrent edits of the order wait and then see the new version
+	var current int
+	err := tx.QueryRowContext(ctx, `SELECT version FROM orders WHERE id = $1 FOR UPDATE`, order.ID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("could not get order: %w", err)
+	}
+	if current != version {
+		return ErrVersionConflict
+	}
+
+	queryRevision := `INSERT INTO order_revisions
+	(order_id, version, number_of_items, catalog_version, strategy, cost, shipping)
+	SELECT o.id, o.version, o.number_of_items, o.catalog_version, o.strategy, o.cost, ` + orderShippingJSON + `
+	FROM orders o WHERE o.id = $1`
+	if _, err := tx.ExecContext(ctx, queryRevision, order.ID); err != nil {
+		return fmt.Errorf("could not insert order revision: %w", err)
+	}
+
+	if err := returnPackStock(ctx, tx, order.ID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM order_shipping WHERE order_id = $1`, order.ID); err != nil {
+		return fmt.Errorf("could not delete order shipping: %w", err)
+	}
+
+	query := `UPDATE orders SET number_of_items = $2, catalog_version = $3, cost = $4, version = version + 1,
+	updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING version, updated_at`
+	row := tx.QueryRowContext(ctx, query, order.ID, order.NumberOfItems, order.CatalogVersion, order.Cost)
+	if err := row.Scan(&order.Version, &order.UpdateAt); err != nil {
+		return fmt.Errorf("could not update order: %w", err)
+	}
+
+	order.Shipping = nil
+	for _, v := range orderShipping {
+		if err := insertOrderShipping(ctx, tx, order.ID, v); err != nil {
+			return err
+		}
+		order.Shipping = append(order.Shipping, *v)
+	}
+
+	return takePackStock(ctx, tx, orderPacksUsed(order, orderShipping))
+}
+
+// getOrderRevisions returns the previous packings of an order, oldest first
+func (ps *postgresService) getOrderRevisions(ctx context.Context, orderID int) ([]models.OrderRevision, error) {
+	query := `SELECT id, order_id, version, number_of_items, catalog_version, strategy, cost, shipping, created_at
+	FROM order_revisions WHERE order_id = $1 ORDER BY version`
+	rows, err := ps.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding revisions for order: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []models.OrderRevision
+	for rows.Next() {
+		var revision models.OrderRevision
+		var shipping []byte
+		err := rows.Scan(&revision.ID, &revision.OrderID, &revision.Version, &revision.NumberOfItems,
+			&revision.CatalogVersion, &revision.Strategy, &revision.Cost, &shipping, &revision.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("could not get order revision: %w", err)
+		}
+		if err := json.Unmarshal(shipping, &revision.Shipping); err != nil {
+			return nil, fmt.Errorf("could not decode order revision shipping: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get order revisions: %w", err)
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+// TransitionOrder moves an order from transition.From to transition.To and
+// records the transition. It returns ErrStatusChanged when the order is not
+// in transition.From anymore, or ErrOrderDeleted when it was deleted. The packs
+// of a cancelled order go back in stock.
+func (ps *postgresService) TransitionOrder(ctx context.Context, transition *models.OrderTransition) error {
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to start db transaction: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE orders SET status = $3, version = version + 1, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND status = $2 AND deleted_at IS NULL`, transition.OrderID, transition.From, transition.To)
+	if err != nil {
+		return errors.Join(fmt.Errorf("could not update order status: %w", err), rollback(tx))
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return errors.Join(fmt.Errorf("could not update order status: %w", err), rollback(tx))
+	}
+	if n == 0 {
+		return errors.Join(transitionUnchanged(ctx, tx, transition.OrderID), rollback(tx))
+	}
+
+	if err := insertOrderTransition(ctx, tx, transition); err != nil {
+		return errors.Join(err, rollback(tx))
+	}
+
+	if transition.To == models.OrderCancelled {
+		if err := returnPackStock(ctx, tx, transition.OrderID); err != nil {
+			return errors.Join(err, rollback(tx))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit db transaction: %w", err)
+	}
+
+	return nil
+}
+
+// transitionUnchanged returns why an order was not moved, ErrOrderDeleted
+// when it was deleted or else ErrStatusChanged
+func transitionUnchanged(ctx context.Context, tx *sql.Tx, orderID int) error {
+	var deleted bool
+	err := tx.QueryRowContext(ctx, `SELECT deleted_at IS NOT NULL FROM orders WHERE id = $1`, orderID).Scan(&deleted)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("could not get order: %w", err)
+	}
+	if deleted {
+		return ErrOrderDeleted
+	}
+
+	return ErrStatusChanged
+}
+
+func insertOrderTransition(ctx context.Context, tx *sql.Tx, transition *models.OrderTransition) error {
+	query := `INSERT INTO order_transitions (id, order_id, from_status, to_status) VALUES (DEFAULT, $1, $2, $3)
+	RETURNING id, created_at`
+	row := tx.QueryRowContext(ctx, query, transition.OrderID, transition.From, transition.To)
+	if err := row.Scan(&transition.ID, &transition.CreatedAt); err != nil {
+		return fmt.Errorf("could not insert order transition: %w", err)
+	}
+
+	return nil
+}
+
+// getOrderTransitions returns the statuses an order moved to, oldest first
+func (ps *postgresService) getOrderTransitions(ctx context.Context, orderID int) ([]models.OrderTransition, error) {
+	query := `SELECT id, order_id, from_status, to_status, created_at FROM order_transitions
+	WHERE order_id = $1 ORDER BY id`
+	rows, err := ps.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding transitions for order: %w", err)
+	}
+	defer rows.Close()
+
+	var transitions []models.OrderTransition
+	for rows.Next() {
+		var transition models.OrderTransition
+		err := rows.Scan(&transition.ID, &transition.OrderID, &transition.From, &transition.To, &transition.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("could not get order transition: %w", err)
+		}
+		transitions = append(transitions, transition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get order transitions: %w", err)
+	}
+
+	return transitions, nil
+}
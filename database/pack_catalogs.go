@@ -0,0 +1,248 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+const packCatalogColumns = `version, product_id, active, shipment_fee, created_at, updated_at`
+
+// catalogEdit changes a copy of a catalog, the result is stored as a new catalog version
+type catalogEdit func(catalog *models.PackCatalog) error
+
+func scanPackCatalog(row interface{ Scan(dest ...any) error }, catalog *models.PackCatalog) error {
+	return row.Scan(&catalog.Version, &catalog.ProductID, &catalog.Active, &catalog.ShipmentFee,
+		&catalog.CreatedAt, &catalog.UpdateAt)
+}
+
+// GetAvailableShippingPacks returns the packs of the active catalog, largest first
+func (ps *postgresService) GetAvailableShippingPacks(ctx context.Context) ([]models.ShippingPack, error) {
+	catalog, err := ps.GetActivePackCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return catalog.Packs, nil
+}
+
+// GetActivePackCatalog returns the global catalog new orders are packed against
+func (ps *postgresService) GetActivePackCatalog(ctx context.Context) (*models.PackCatalog, error) {
+	return getActivePackCatalog(ctx, ps.db, nil)
+}
+
+// getActivePackCatalog returns the active catalog of a product, or the
+// global one when productID is nil
+func getActivePackCatalog(ctx context.Context, q queryer, productID *int) (*models.PackCatalog, error) {
+	query := `SELECT ` + packCatalogColumns + ` FROM pack_catalogs WHERE active AND product_id IS NOT DISTINCT FROM $1`
+	return getPackCatalog(ctx, q, query, productID)
+}
+
+// GetPackCatalog returns the catalog with the given version
+func (ps *postgresService) GetPackCatalog(ctx context.Context, version int) (*models.PackCatalog, error) {
+	return getPackCatalog(ctx, ps.db, `SELECT `+packCatalogColumns+` FROM pack_catalogs WHERE version = $1`, version)
+}
+
+func getPackCatalog(ctx context.Context, q queryer, query string, args ...any) (*models.PackCatalog, error) {
+	var catalog models.PackCatalog
+	err := scanPackCatalog(q.QueryRowContext(ctx, query, args...), &catalog)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get pack catalog: %w", err)
+	}
+
+	catalog.Packs, err = getCatalogPacks(ctx, q, catalog.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &catalog, nil
+}
+
+// GetPackCatalogs returns every catalog version, newest first
+func (ps *postgresService) GetPackCatalogs(ctx context.Context) ([]models.PackCatalog, error) {
+	query := `SELECT ` + packCatalogColumns + ` FROM pack_catalogs ORDER BY version DESC`
+	rows, err := ps.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error query db for pack catalogs: %w", err)
+	}
+	defer rows.Close()
+
+	var catalogs []models.PackCatalog
+	for rows.Next() {
+		var catalog models.PackCatalog
+		if err := scanPackCatalog(rows, &catalog); err != nil {
+			return nil, fmt.Errorf("could not get pack catalog: %w", err)
+		}
+		catalogs = append(catalogs, catalog)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning columns from pack catalog: %w", err)
+	}
+
+	for i := range catalogs {
+		catalogs[i].Packs, err = getCatalogPacks(ctx, ps.db, catalogs[i].Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return catalogs, nil
+}
+
+// ActivatePackCatalog makes the catalog with the given version the one new
+// orders are packed against, this is how a bad catalog is rolled back
+func (ps *postgresService) ActivatePackCatalog(ctx context.Context, version int) (*models.PackCatalog, error) {
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to start db transaction: %w", err)
+	}
+
+	if err := lockPackCatalogs(ctx, tx); err != nil {
+		return nil, errors.Join(err, rollback(tx))
+	}
+
+	if err := activatePackCatalog(ctx, tx, version); err != nil {
+		return nil, errors.Join(err, rollback(tx))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit db transaction: %w", err)
+	}
+
+	return ps.GetPackCatalog(ctx, version)
+}
+
+// lockPackCatalogs serialises catalog changes so only one catalog can be
+// activated at a time
+func lockPackCatalogs(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `LOCK TABLE pack_catalogs IN SHARE ROW EXCLUSIVE MODE`)
+	if err != nil {
+		return fmt.Errorf("could not lock pack catalogs: %w", err)
+	}
+
+	return nil
+}
+
+// activatePackCatalog activates a catalog and deactivates the catalog it
+// replaces, the global catalog and each product catalog are replaced separately
+func activatePackCatalog(ctx context.Context, tx *sql.Tx, version int) error {
+	var productID *int
+	err := tx.QueryRowContext(ctx, `SELECT product_id FROM pack_catalogs WHERE version = $1`, version).Scan(&productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("could not get pack catalog: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE pack_catalogs SET active = FALSE, updated_at = CURRENT_TIMESTAMP
+	WHERE active AND product_id IS NOT DISTINCT FROM $1`, productID)
+	if err != nil {
+		return fmt.Errorf("could not deactivate pack catalog: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE pack_catalogs SET active = TRUE, updated_at = CURRENT_TIMESTAMP WHERE version = $1`, version)
+	if err != nil {
+		return fmt.Errorf("could not activate pack catalog: %w", err)
+	}
+
+	return nil
+}
+
+// editActiveCatalog copies the packs of the active global catalog, applies
+// edit to them and stores the result as a new active catalog version. Catalog
+// versions are never modified so orders can always be traced to the packs they used.
+func (ps *postgresService) editActiveCatalog(ctx context.Context, edit catalogEdit) (*models.PackCatalog, error) {
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to start db transaction: %w", err)
+	}
+
+	if err := lockPackCatalogs(ctx, tx); err != nil {
+		return nil, errors.Join(err, rollback(tx))
+	}
+
+	catalog, err := getActivePackCatalog(ctx, tx, nil)
+	if err != nil {
+		return nil, errors.Join(err, rollback(tx))
+	}
+
+	if err := edit(catalog); err != nil {
+		return nil, errors.Join(err, rollback(tx))
+	}
+
+	catalog, err = createPackCatalog(ctx, tx, catalog)
+	if err != nil {
+		return nil, errors.Join(err, rollback(tx))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit db transaction: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// createPackCatalog stores the packs and shipment fee of draft as a new
+// catalog version and activates it, the caller must hold the pack catalogs lock
+func createPackCatalog(ctx context.Context, tx *sql.Tx, draft *models.PackCatalog) (*models.PackCatalog, error) {
+	if err := validateCatalogPacks(draft.Packs); err != nil {
+		return nil, err
+	}
+
+	var catalog models.PackCatalog
+	query := `INSERT INTO pack_catalogs (version, product_id, active, shipment_fee) VALUES (DEFAULT, $1, FALSE, $2)
+	RETURNING ` + packCatalogColumns
+	if err := scanPackCatalog(tx.QueryRowContext(ctx, query, draft.ProductID, draft.ShipmentFee), &catalog); err != nil {
+		return nil, fmt.Errorf("could not insert pack catalog: %w", err)
+	}
+
+	packs := slices.Clone(draft.Packs)
+	slices.SortFunc(packs, func(a, b models.ShippingPack) int { return b.Quantity - a.Quantity })
+
+	queryPack := `INSERT INTO shipping_packs (id, catalog_version, quantity, unit_cost) VALUES (DEFAULT, $1, $2, $3)`
+	for _, pack := range packs {
+		if _, err := tx.ExecContext(ctx, queryPack, catalog.Version, pack.Quantity, pack.UnitCost); err != nil {
+			return nil, fmt.Errorf("could not insert shipping pack: %w", err)
+		}
+	}
+
+	// read the packs back to get their ids and stock
+	var err error
+	catalog.Packs, err = getCatalogPacks(ctx, tx, catalog.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := activatePackCatalog(ctx, tx, catalog.Version); err != nil {
+		return nil, err
+	}
+	catalog.Active = true
+
+	return &catalog, nil
+}
+
+// validateCatalogPacks checks a catalog can be used to pack orders
+func validateCatalogPacks(packs []models.ShippingPack) error {
+	if len(packs) == 0 {
+		return ErrLastShippingPack
+	}
+
+	seen := make(map[int]bool, len(packs))
+	for _, pack := range packs {
+		if seen[pack.Quantity] {
+			return ErrDuplicatePackSize
+		}
+		seen[pack.Quantity] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/lib/pq"
+	"github.com/spankie/gymshark/database/models"
+)
+
+const packStockColumns = `pack_size, quantity, created_at, updated_at`
+
+func scanPackStock(row interface{ Scan(dest ...any) error }, stock *models.PackStock) error {
+	return row.Scan(&stock.PackSize, &stock.Quantity, &stock.CreatedAt, &stock.UpdateAt)
+}
+
+// GetPackStock returns the stock of every pack size whose stock is tracked, largest first
+func (ps *postgresService) GetPackStock(ctx context.Context) ([]models.PackStock, error) {
+	query := `SELECT ` + packStockColumns + ` FROM pack_stock ORDER BY pack_size DESC`
+	rows, err := ps.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error query db for pack stock: %w", err)
+	}
+	defer rows.Close()
+
+	var stock []models.PackStock
+	for rows.Next() {
+		var s models.PackStock
+		if err := scanPackStock(rows, &s); err != nil {
+			return nil, fmt.Errorf("could not get pack stock: %w", err)
+		}
+		stock = append(stock, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning columns from pack stock: %w", err)
+	}
+
+	return stock, nil
+}
+
+// SetPackStock sets the number of packs of a size in stock, it starts
+// tracking the stock of the size if it was not tracked
+func (ps *postgresService) SetPackStock(ctx context.Context, stock *models.PackStock) error {
+	query := `INSERT INTO pack_stock (pack_size, quantity) VALUES ($1, $2)
+	ON CONFLICT (pack_size) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = CURRENT_TIMESTAMP
+	RETURNING ` + packStockColumns
+	row := ps.db.QueryRowContext(ctx, query, stock.PackSize, stock.Quantity)
+	if err := scanPackStock(row, stock); err != nil {
+		return fmt.Errorf("could not set pack stock: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePackStock stops tracking the stock of a pack size, the size then has an unlimited supply
+func (ps *postgresService) DeletePackStock(ctx context.Context, packSize int) error {
+	result, err := ps.db.ExecContext(ctx, `DELETE FROM pack_stock WHERE pack_size = $1`, packSize)
+	if err != nil {
+		return fmt.Errorf("could not delete pack stock: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not delete pack stock: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// takePackStock removes the packs used by an order from the stock. The stock
+// rows are locked until the transaction ends so concurrent orders cannot use
+// the same packs, it returns ErrInsufficientStock when a size has run out
+// since the order was packed.
+func takePackStock(ctx context.Context, tx *sql.Tx, used map[int]int) error {
+	// lock the rows in the same order in every transaction to avoid deadlocks
+	sizes := slices.Sorted(maps.Keys(used))
+	query := `SELECT pack_size, quantity FROM pack_stock WHERE pack_size = ANY($1) ORDER BY pack_size FOR UPDATE`
+	rows, err := tx.QueryContext(ctx, query, pq.Array(sizes))
+	if err != nil {
+		return fmt.Errorf("could not lock pack stock: %w", err)
+	}
+	defer rows.Close()
+
+	stock := make(map[int]int)
+	for rows.Next() {
+		var size, quantity int
+		if err := rows.Scan(&size, &quantity); err != nil {
+			return fmt.Errorf("could not get pack stock: %w", err)
+		}
+		stock[size] = quantity
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error scanning columns from pack stock: %w", err)
+	}
+
+	for _, size := range sizes {
+		quantity, tracked := stock[size]
+		if !tracked {
+			continue
+		}
+		if quantity < used[size] {
+			return fmt.Errorf("%w: %d packs of %d needed, %d left", ErrInsufficientStock, used[size], size, quantity)
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE pack_stock SET quantity = quantity - $2, updated_at = CURRENT_TIMESTAMP
+		WHERE pack_size = $1`, size, used[size])
+		if err != nil {
+			return fmt.Errorf("could not update pack stock: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// orderPacksUsed returns the number of packs of each size used by an order
+func orderPacksUsed(order *models.Order, orderShipping []*models.OrderShipping) map[int]int {
+	used := make(map[int]int)
+	for _, line := range order.Lines {
+		for _, shipping := range line.Shipping {
+			used[shipping.PackSize] += shipping.ShippingPackQuantity
+		}
+	}
+	for _, shipping := range orderShipping {
+		used[shipping.PackSize] += shipping.ShippingPackQuantity
+	}
+
+	return used
+}
+
+// returnPackStock puts the packs used by an order back in stock, for the pack
+// sizes whose stock is tracked
+func returnPackStock(ctx context.Context, tx *sql.Tx, orderID int) error {
+	query := `UPDATE pack_stock st SET quantity = st.quantity + s.total, updated_at = CURRENT_TIMESTAMP
+	FROM (SELECT pack_size, SUM(shipping_pack_quantity) AS total FROM order_shipping
+		WHERE order_id = $1 GROUP BY pack_size) s
+	WHERE st.pack_size = s.pack_size`
+	if _, err := tx.ExecContext(ctx, query, orderID); err != nil {
+		return fmt.Errorf("could not return pack stock: %w", err)
+	}
+
+	return nil
+}
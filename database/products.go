@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+const productColumns = `id, sku, name, created_at, updated_at`
+
+func scanProduct(row interface{ Scan(dest ...any) error }, product *models.Product) error {
+	return row.Scan(&product.ID, &product.SKU, &product.Name, &product.CreatedAt, &product.UpdateAt)
+}
+
+// CreateProduct inserts a product, a catalog is created for the product when
+// packSizes is not empty
+func (ps *postgresService) CreateProduct(ctx context.Context, product *models.Product, packSizes []int) error {
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to start db transaction: %w", err)
+	}
+
+	query := `INSERT INTO products (id, sku, name) VALUES (DEFAULT, $1, $2) RETURNING ` + productColumns
+	err = scanProduct(tx.QueryRowContext(ctx, query, product.SKU, product.Name), product)
+	if isUniqueViolation(err) {
+		return errors.Join(ErrDuplicateSKU, rollback(tx))
+	}
+	if err != nil {
+		return errors.Join(fmt.Errorf("could not insert product: %w", err), rollback(tx))
+	}
+
+	if len(packSizes) > 0 {
+		product.Catalog, err = createProductCatalog(ctx, tx, product.ID, packSizes)
+		if err != nil {
+			return errors.Join(err, rollback(tx))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit db transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetProduct returns the product with the given id and its active catalog
+func (ps *postgresService) GetProduct(ctx context.Context, id int) (*models.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = $1`
+
+	var product models.Product
+	err := scanProduct(ps.db.QueryRowContext(ctx, query, id), &product)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get product: %w", err)
+	}
+
+	if err := ps.loadProductCatalog(ctx, &product); err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// GetProducts returns every product and its active catalog
+func (ps *postgresService) GetProducts(ctx context.Context) ([]models.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products ORDER BY id`
+	rows, err := ps.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error query db for products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var product models.Product
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, fmt.Errorf("could not get product: %w", err)
+		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning columns from product: %w", err)
+	}
+
+	for i := range products {
+		if err := ps.loadProductCatalog(ctx, &products[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return products, nil
+}
+
+// SetProductPacks replaces the pack sizes of a product with a new catalog version
+func (ps *postgresService) SetProductPacks(ctx context.Context, productID int, packSizes []int) (*models.PackCatalog, error) {
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to start db transaction: %w", err)
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)`, productID).Scan(&exists)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("could not get product: %w", err), rollback(tx))
+	}
+	if !exists {
+		return nil, errors.Join(ErrNotFound, rollback(tx))
+	}
+
+	catalog, err := createProductCatalog(ctx, tx, productID, packSizes)
+	if err != nil {
+		return nil, errors.Join(err, rollback(tx))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit db transaction: %w", err)
+	}
+
+	return catalog, nil
+}
+
+func createProductCatalog(ctx context.Context, tx *sql.Tx, productID int, packSizes []int) (*models.PackCatalog, error) {
+	if err := lockPackCatalogs(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	catalog := &models.PackCatalog{ProductID: &productID}
+	for _, size := range packSizes {
+		catalog.Packs = append(catalog.Packs, models.ShippingPack{Quantity: size})
+	}
+
+	return createPackCatalog(ctx, tx, catalog)
+}
+
+// loadProductCatalog sets the active catalog of the product, if it has one
+func (ps *postgresService) loadProductCatalog(ctx context.Context, product *models.Product) error {
+	catalog, err := getActivePackCatalog(ctx, ps.db, &product.ID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	product.Catalog = catalog
+	return nil
+}
@@ -0,0 +1,346 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+const repackJobColumns = `id, catalog_version, mode, filter, status, last_order_id, total, processed, changed,
+	failed, overshoot_change, packs_change, error, created_at, updated_at`
+
+func scanRepackJob(row interface{ Scan(dest ...any) error }, job *models.RepackJob) error {
+	var filter []byte
+	err := row.Scan(&job.ID, &job.CatalogVersion, &job.Mode, &filter, &job.Status, &job.LastOrderID, &job.Total,
+		&job.Processed, &job.Changed, &job.Failed, &job.OvershootChange, &job.PacksChange, &job.Error,
+		&job.CreatedAt, &job.UpdateAt)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(filter, &job.Filter)
+}
+
+// RepackedOrder is an order packed by a repack job. Shipping is the new
+// packing of the order, made from the order at Version.
+type RepackedOrder struct {
+	Order    *models.Order
+	Version  int
+	Shipping []*models.OrderShipping
+	Result   *models.RepackResult
+}
+
+// repackConditions returns the conditions orders must meet to be repacked with filter
+func repackConditions(filter models.RepackFilter, args *queryArgs) []string {
+	conditions := orderConditions(OrdersFilter{
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+		MinItems:    filter.MinItems,
+		MaxItems:    filter.MaxItems,
+		PackSize:    filter.PackSize,
+		Status:      filter.Status,
+		CustomerID:  filter.CustomerID,
+	}, args)
+
+	// the lines of an order are packed with the catalogs of their products
+	return append(conditions, "NOT EXISTS (SELECT 1 FROM order_lines l WHERE l.order_id = o.id)")
+}
+
+// CreateRepackJob creates a repack job, its total is the number of orders its filter selects
+func (ps *postgresService) CreateRepackJob(ctx context.Context, job *models.RepackJob) error {
+	filter, err := json.Marshal(job.Filter)
+	if err != nil {
+		return fmt.Errorf("could not encode repack filter: %w", err)
+	}
+
+	var args queryArgs
+	query := fmt.Sprintf(`INSERT INTO repack_jobs (catalog_version, mode, filter, total)
+	SELECT %s::int, %s::text, %s::jsonb, COUNT(*) FROM orders o WHERE %s RETURNING `+repackJobColumns,
+		args.add(job.CatalogVersion), args.add(job.Mode), args.add(string(filter)),
+		strings.Join(repackConditions(job.Filter, &args), " AND "))
+	row := ps.db.QueryRowContext(ctx, query, args...)
+	if err := scanRepackJob(row, job); err != nil {
+		return fmt.Errorf("could not create repack job: %w", err)
+	}
+
+	return nil
+}
+
+// GetRepackJob returns the repack job with the given id
+func (ps *postgresService) GetRepackJob(ctx context.Context, id int) (*models.RepackJob, error) {
+	job := &models.RepackJob{}
+	row := ps.db.QueryRowContext(ctx, `SELECT `+repackJobColumns+` FROM repack_jobs WHERE id = $1`, id)
+	err := scanRepackJob(row, job)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get repack job: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetRepackJobs returns every repack job, newest first
+func (ps *postgresService) GetRepackJobs(ctx context.Context) ([]models.RepackJob, error) {
+	rows, err := ps.db.QueryContext(ctx, `SELECT `+repackJobColumns+` FROM repack_jobs ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("error getting repack jobs from db: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.RepackJob{}
+	for rows.Next() {
+		var job models.RepackJob
+		if err := scanRepackJob(rows, &job); err != nil {
+			return nil, fmt.Errorf("could not get repack job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get repack jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// ClaimRepackJob marks a repack job as running so a single worker runs it. A
+// pending or failed job can be claimed, and a running job that has not saved
+// a batch for longer than stale, its worker has stopped. It returns
+// ErrRepackJobNotResumable when the job is completed or still running.
+func (ps *postgresService) ClaimRepackJob(ctx context.Context, id int, stale time.Duration) (*models.RepackJob, error) {
+	query := `UPDATE repack_jobs SET status = $2, error = NULL, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND (status IN ($3, $4) OR (status = $2 AND updated_at < CURRENT_TIMESTAMP - make_interval(secs => $5)))
+	RETURNING ` + repackJobColumns
+	job := &models.RepackJob{}
+	row := ps.db.QueryRowContext(ctx, query, id, models.RepackRunning, models.RepackPending, models.RepackFailed,
+		stale.Seconds())
+	err := scanRepackJob(row, job)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := ps.GetRepackJob(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrRepackJobNotResumable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not claim repack job: %w", err)
+	}
+
+	return job, nil
+}
+
+// FinishRepackJob sets the final status of a repack job, message is why it failed
+func (ps *postgresService) FinishRepackJob(ctx context.Context, job *models.RepackJob, status string,
+	message *string) error {
+	query := `UPDATE repack_jobs SET status = $2, error = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+	RETURNING ` + repackJobColumns
+	if err := scanRepackJob(ps.db.QueryRowContext(ctx, query, job.ID, status, message), job); err != nil {
+		return fmt.Errorf("could not finish repack job: %w", err)
+	}
+
+	return nil
+}
+
+// GetRepackOrders returns the orders a repack job with filter processes after
+// the order afterID, at most limit orders in id order. The shipping of each
+// order is summed per pack size.
+func (ps *postgresService) GetRepackOrders(ctx context.Context, filter models.RepackFilter, afterID,
+	limit int) ([]models.Order, error) {
+	var args queryArgs
+	conditions := append(repackConditions(filter, &args), "o.id > "+args.add(afterID))
+	query := fmt.Sprintf(`SELECT o.id, o.number_of_items, o.catalog_version, o.strategy, o.cost, o.customer_id,
+	o.status, o.version, o.created_at FROM orders o WHERE %s ORDER BY o.id LIMIT %s`,
+		strings.Join(conditions, " AND "), args.add(limit))
+	rows, err := ps.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting repack orders from db: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []models.Order{}
+	for rows.Next() {
+		order := models.Order{}
+		err := rows.Scan(&order.ID, &order.NumberOfItems, &order.CatalogVersion, &order.Strategy, &order.Cost,
+			&order.CustomerID, &order.Status, &order.Version, &order.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("could not get repack order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get repack orders: %w", err)
+	}
+
+	if err := ps.addOrdersShipping(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// SaveRepackBatch saves the results of a batch of orders of a repack job and
+// the progress of the job in one transaction, so a stopped job resumes after
+// the last batch saved. In apply mode the shipping of each changed order is
+// replaced, an order that cannot be changed anymore, e.g. because it was
+// edited since it was packed, gets an error and the rest of the batch is saved.
+func (ps *postgresService) SaveRepackBatch(ctx context.Context, job *models.RepackJob, batch []RepackedOrder) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	tx, err := ps.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to start db transaction: %w", err)
+	}
+
+	for _, repacked := range batch {
+		if job.Mode == models.RepackApply && repacked.Result.Changed && repacked.Result.Error == nil {
+			if err := applyRepack(ctx, tx, repacked); err != nil {
+				return errors.Join(err, rollback(tx))
+			}
+		}
+		if err := insertRepackResult(ctx, tx, repacked.Result); err != nil {
+			return errors.Join(err, rollback(tx))
+		}
+	}
+
+	changed, failed, overshootChange, packsChange := repackProgress(batch)
+	query := `UPDATE repack_jobs SET last_order_id = $2, processed = processed + $3, changed = changed + $4,
+	failed = failed + $5, overshoot_change = overshoot_change + $6, packs_change = packs_change + $7,
+	updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING ` + repackJobColumns
+	row := tx.QueryRowContext(ctx, query, job.ID, batch[len(batch)-1].Order.ID, len(batch), changed, failed,
+		overshootChange, packsChange)
+	if err := scanRepackJob(row, job); err != nil {
+		return errors.Join(fmt.Errorf("could not save repack job progress: %w", err), rollback(tx))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit db transaction: %w", err)
+	}
+
+	return nil
+}
+
+// repackProgress returns the number of orders of a batch that changed and
+// failed, and how the overshoot and packs of the batch changed
+func repackProgress(batch []RepackedOrder) (int, int, int, int) {
+	var changed, failed, overshootChange, packsChange int
+	for _, repacked := range batch {
+		result := repacked.Result
+		if result.Error != nil {
+			failed++
+			continue
+		}
+		if result.Changed {
+			changed++
+		}
+		overshootChange += *result.NewOvershoot - result.OldOvershoot
+		packsChange += *result.NewPacks - result.OldPacks
+	}
+
+	return changed, failed, overshootChange, packsChange
+}
+
+// applyRepack replaces the shipping of a repacked order. The order is amended
+// within a savepoint, when it cannot be amended the savepoint is rolled back
+// and the error is set on the result of the order.
+func applyRepack(ctx context.Context, tx *sql.Tx, repacked RepackedOrder) error {
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT repack_order`); err != nil {
+		return fmt.Errorf("could not create savepoint: %w", err)
+	}
+
+	err := amendOrder(ctx, tx, repacked.Order, repacked.Shipping, repacked.Version)
+	if errors.Is(err, ErrVersionConflict) || errors.Is(err, ErrInsufficientStock) || errors.Is(err, ErrNotFound) {
+		message := err.Error()
+		repacked.Result.Error = &message
+		if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT repack_order`); err != nil {
+			return fmt.Errorf("could not roll back to savepoint: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	repacked.Result.Applied = true
+	if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT repack_order`); err != nil {
+		return fmt.Errorf("could not release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+func insertRepackResult(ctx context.Context, tx *sql.Tx, result *models.RepackResult) error {
+	oldShipping, err := json.Marshal(result.OldShipping)
+	if err != nil {
+		return fmt.Errorf("could not encode repack shipping: %w", err)
+	}
+	// the new shipping is null when the order could not be packed
+	var newShipping *string
+	if result.NewShipping != nil {
+		b, err := json.Marshal(result.NewShipping)
+		if err != nil {
+			return fmt.Errorf("could not encode repack shipping: %w", err)
+		}
+		shipping := string(b)
+		newShipping = &shipping
+	}
+
+	query := `INSERT INTO repack_results (job_id, order_id, old_shipping, new_shipping, old_overshoot, new_overshoot,
+	old_packs, new_packs, changed, applied, error) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	RETURNING created_at`
+	row := tx.QueryRowContext(ctx, query, result.JobID, result.OrderID, string(oldShipping), newShipping, result.OldOvershoot,
+		result.NewOvershoot, result.OldPacks, result.NewPacks, result.Changed, result.Applied, result.Error)
+	if err := row.Scan(&result.CreatedAt); err != nil {
+		return fmt.Errorf("could not insert repack result: %w", err)
+	}
+
+	return nil
+}
+
+// GetRepackResults returns the results of a repack job after the order
+// afterID, at most limit results in order id order. Only the orders whose
+// packing changed or failed are returned when changedOnly is set.
+func (ps *postgresService) GetRepackResults(ctx context.Context, jobID, afterID, limit int,
+	changedOnly bool) ([]models.RepackResult, error) {
+	query := `SELECT job_id, order_id, old_shipping, new_shipping, old_overshoot, new_overshoot, old_packs, new_packs,
+	changed, applied, error, created_at FROM repack_results
+	WHERE job_id = $1 AND order_id > $2 AND (NOT $3 OR changed OR error IS NOT NULL) ORDER BY order_id LIMIT $4`
+	rows, err := ps.db.QueryContext(ctx, query, jobID, afterID, changedOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting repack results from db: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.RepackResult{}
+	for rows.Next() {
+		var result models.RepackResult
+		var oldShipping, newShipping []byte
+		err := rows.Scan(&result.JobID, &result.OrderID, &oldShipping, &newShipping, &result.OldOvershoot,
+			&result.NewOvershoot, &result.OldPacks, &result.NewPacks, &result.Changed, &result.Applied, &result.Error,
+			&result.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("could not get repack result: %w", err)
+		}
+		if err := json.Unmarshal(oldShipping, &result.OldShipping); err != nil {
+			return nil, fmt.Errorf("could not decode repack shipping: %w", err)
+		}
+		if newShipping != nil {
+			if err := json.Unmarshal(newShipping, &result.NewShipping); err != nil {
+				return nil, fmt.Errorf("could not decode repack shipping: %w", err)
+			}
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get repack results: %w", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// shippingPacksTable joins the packs with the stock of their size
+const shippingPacksTable = `shipping_packs s LEFT JOIN pack_stock st ON st.pack_size = s.quantity`
+
+const shippingPackColumns = `s.id, s.catalog_version, s.quantity, s.unit_cost, st.quantity, s.created_at, s.updated_at`
+
+func scanShippingPack(row interface{ Scan(dest ...any) error }, pack *models.ShippingPack) error {
+	return row.Scan(&pack.ID, &pack.CatalogVersion, &pack.Quantity, &pack.UnitCost, &pack.Stock,
+		&pack.CreatedAt, &pack.UpdateAt)
+}
+
+// getCatalogPacks returns the shipping packs of a catalog version, largest first
+func getCatalogPacks(ctx context.Context, q queryer, version int) ([]models.ShippingPack, error) {
+	query := `SELECT ` + shippingPackColumns + ` FROM ` + shippingPacksTable + ` WHERE s.catalog_version = $1 ORDER BY s.quantity DESC`
+	rows, err := q.QueryContext(ctx, query, version)
+	if err != nil {
+		return nil, fmt.Errorf("error query db for shipping packs: %w", err)
+	}
+	defer rows.Close()
+
+	var packs []models.ShippingPack
+	for rows.Next() {
+		var pack models.ShippingPack
+		if err := scanShippingPack(rows, &pack); err != nil {
+			return nil, fmt.Errorf("could not get shipping pack: %w", err)
+		}
+		packs = append(packs, pack)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning columns from shipping pack: %w", err)
+	}
+
+	return packs, nil
+}
+
+// GetShippingPack returns the shipping pack with the given id
+func (ps *postgresService) GetShippingPack(ctx context.Context, id int) (*models.ShippingPack, error) {
+	query := `SELECT ` + shippingPackColumns + ` FROM ` + shippingPacksTable + ` WHERE s.id = $1`
+	row := ps.db.QueryRowContext(ctx, query, id)
+
+	var pack models.ShippingPack
+	err := scanShippingPack(row, &pack)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get shipping pack: %w", err)
+	}
+
+	return &pack, nil
+}
+
+// CreateShippingPack adds a new pack size to a new version of the active catalog
+func (ps *postgresService) CreateShippingPack(ctx context.Context, pack *models.ShippingPack) error {
+	catalog, err := ps.editActiveCatalog(ctx, func(catalog *models.PackCatalog) error {
+		catalog.Packs = append(catalog.Packs, *pack)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*pack = catalogPack(catalog, pack.Quantity)
+	return nil
+}
+
+// UpdateShippingPack resizes and reprices a pack of the active catalog in a new catalog version
+func (ps *postgresService) UpdateShippingPack(ctx context.Context, pack *models.ShippingPack) error {
+	catalog, err := ps.editActiveCatalog(ctx, func(catalog *models.PackCatalog) error {
+		i := slices.IndexFunc(catalog.Packs, func(p models.ShippingPack) bool { return p.ID == pack.ID })
+		if i < 0 {
+			return ErrNotFound
+		}
+		catalog.Packs[i].Quantity = pack.Quantity
+		catalog.Packs[i].UnitCost = pack.UnitCost
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*pack = catalogPack(catalog, pack.Quantity)
+	return nil
+}
+
+// DeleteShippingPack retires a pack of the active catalog in a new catalog version
+func (ps *postgresService) DeleteShippingPack(ctx context.Context, id int) (*models.PackCatalog, error) {
+	return ps.editActiveCatalog(ctx, func(catalog *models.PackCatalog) error {
+		i := slices.IndexFunc(catalog.Packs, func(p models.ShippingPack) bool { return p.ID == id })
+		if i < 0 {
+			return ErrNotFound
+		}
+		catalog.Packs = slices.Delete(catalog.Packs, i, i+1)
+		return nil
+	})
+}
+
+// SetShipmentFee changes the shipment fee of the active catalog in a new catalog version
+func (ps *postgresService) SetShipmentFee(ctx context.Context, fee int) (*models.PackCatalog, error) {
+	return ps.editActiveCatalog(ctx, func(catalog *models.PackCatalog) error {
+		catalog.ShipmentFee = fee
+		return nil
+	})
+}
+
+// catalogPack finds the pack with the given size in the catalog
+func catalogPack(catalog *models.PackCatalog, quantity int) models.ShippingPack {
+	i := slices.IndexFunc(catalog.Packs, func(p models.ShippingPack) bool { return p.Quantity == quantity })
+	return catalog.Packs[i]
+}
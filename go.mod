@@ -4,9 +4,11 @@ go 1.23.0
 
 require (
 	github.com/aws/aws-lambda-go v1.47.0
+	github.com/boombuler/barcode v1.1.0
 	github.com/docker/go-connections v0.5.0
 	github.com/gin-contrib/cors v1.7.2
 	github.com/gin-gonic/gin v1.10.0
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/golang-migrate/migrate/v4 v4.18.1
 	github.com/joho/godotenv v1.5.1
 	github.com/kelseyhightower/envconfig v1.4.0
@@ -0,0 +1,103 @@
+package packing_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spankie/gymshark/pkg/packing"
+)
+
+func ExampleSolve() {
+	problem := packing.Problem{
+		Items:     12001,
+		Packs:     []packing.Pack{{Size: 250}, {Size: 500}, {Size: 1000}, {Size: 2000}, {Size: 5000}},
+		Objective: packing.LeastOvershoot,
+	}
+
+	result, err := packing.Solve(context.Background(), problem)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, p := range result.Packs {
+		fmt.Printf("%d x %d\n", p.Count, p.Size)
+	}
+	fmt.Printf("%d items, %d over\n", result.TotalItems, result.Overshoot)
+	// Output:
+	// 2 x 5000
+	// 1 x 2000
+	// 1 x 250
+	// 12250 items, 249 over
+}
+
+func ExampleSolve_stock() {
+	stock := 1
+	problem := packing.Problem{
+		Items: 750,
+		Packs: []packing.Pack{{Size: 250, Cost: 3}, {Size: 500, Cost: 5, Stock: &stock}},
+	}
+
+	result, err := packing.Solve(context.Background(), problem)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(result.Counts(), "cost", result.Cost)
+	// Output:
+	// map[250:1 500:1] cost 8
+}
+
+func ExampleSolve_exact() {
+	problem := packing.Problem{Items: 7, Packs: []packing.Pack{{Size: 3}, {Size: 5}}, Objective: packing.Exact}
+
+	_, err := packing.Solve(context.Background(), problem)
+	fmt.Println(errors.Is(err, packing.ErrNoPacking))
+	// Output:
+	// true
+}
+
+func ExampleRank() {
+	problem := packing.Problem{
+		Items:     501,
+		Packs:     []packing.Pack{{Size: 250}, {Size: 500}, {Size: 1000}, {Size: 2000}, {Size: 5000}},
+		Objective: packing.FewestPacks,
+	}
+
+	ranking, err := packing.Rank(context.Background(), problem, 3)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(ranking.Criteria, ranking.Reason)
+	for _, result := range ranking.Results {
+		fmt.Println(result.Counts(), result.Overshoot)
+	}
+	// Output:
+	// [pack count overshoot] same pack count, lower overshoot
+	// map[1000:1] 499
+	// map[2000:1] 1499
+	// map[5000:1] 4499
+}
+
+func ExampleNewOvershootSolver() {
+	solver, err := packing.NewOvershootSolver(context.Background(), []int{6, 9, 20}, 0)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, items := range []int{43, 44, 1000} {
+		overshoot, packCount := solver.Pack(items)
+		fmt.Printf("%d items: %d over in %d packs\n", items, overshoot, packCount)
+	}
+	fmt.Println("largest quantity that can't ship exactly:", solver.Frobenius())
+	// Output:
+	// 43 items: 1 over in 4 packs
+	// 44 items: 0 over in 4 packs
+	// 1000 items: 0 over in 50 packs
+	// largest quantity that can't ship exactly: 43
+}
@@ -0,0 +1,190 @@
+package packing
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// best is the count and cost of the best packs reaching a total, by cost
+// first or by count first
+type best struct {
+	count, cost int
+	ok          bool
+}
+
+// bruteForce finds the best packs reaching every total up to maxTotal by
+// adding one pack at a time, every pack in stock taken as a separate pack
+func bruteForce(packs []Pack, maxTotal int, byCost bool) []best {
+	table := make([]best, maxTotal+1)
+	table[0].ok = true
+	add := func(size, cost int, fromTable []best) {
+		for x := size; x <= maxTotal; x++ {
+			from := fromTable[x-size]
+			if !from.ok {
+				continue
+			}
+			c := best{count: from.count + 1, cost: from.cost + cost, ok: true}
+			if e := table[x]; !e.ok || c.better(e, byCost) {
+				table[x] = c
+			}
+		}
+	}
+
+	for _, p := range packs {
+		if p.Stock == nil {
+			add(p.Size, p.Cost, table)
+			continue
+		}
+		// more packs than reach maxTotal are never used
+		for range min(*p.Stock, maxTotal/p.Size+1) {
+			// each pack is used once, so it extends the table built before it
+			add(p.Size, p.Cost, slices.Clone(table))
+		}
+	}
+
+	return table
+}
+
+func (b best) better(than best, byCost bool) bool {
+	if byCost && b.cost != than.cost {
+		return b.cost < than.cost
+	}
+	if b.count != than.count {
+		return b.count < than.count
+	}
+
+	return b.cost < than.cost
+}
+
+// fuzzProblem makes a small problem of the fuzzed values, pack sizes are from 1 to 64
+func fuzzProblem(items uint16, sizes []byte, objective byte, stocked bool) Problem {
+	objectives := []Objective{LeastOvershoot, FewestPacks, LeastCost, Exact}
+	p := Problem{Items: int(items % 1000), Objective: objectives[int(objective)%len(objectives)]}
+	for i, b := range sizes[:min(len(sizes), 4)] {
+		pack := Pack{Size: int(b%64) + 1, Cost: int(b/64) + i}
+		if stocked {
+			stock := int(b % 7)
+			pack.Stock = &stock
+		}
+		p.Packs = append(p.Packs, pack)
+	}
+
+	return p
+}
+
+// expectedKey returns the values the best packing of p is ranked by, in the
+// order of its objective, from the brute force table
+func expectedKey(p Problem, table []best) ([]int, bool) {
+	r := rankings[p.Objective]
+	var key []int
+	for x := p.Items; x < len(table); x++ {
+		e := table[x]
+		if !e.ok || (r.exact && x != p.Items) {
+			continue
+		}
+		k := r.key(candidate{sum: x, overshoot: x - p.Items, count: e.count, cost: e.cost})
+		if key == nil || slices.Compare(k, key) < 0 {
+			key = k
+		}
+	}
+
+	return key, key != nil
+}
+
+func (r ranking) key(c candidate) []int {
+	key := make([]int, 0, len(r.criteria))
+	for _, criterion := range r.criteria {
+		key = append(key, criterion.value(c))
+	}
+
+	return key
+}
+
+func FuzzSolve(f *testing.F) {
+	f.Add(uint16(501), []byte{24, 49, 99}, byte(0), false)
+	f.Add(uint16(263), []byte{22, 30, 52}, byte(1), false)
+	f.Add(uint16(10), []byte{2, 68}, byte(2), true)
+	f.Add(uint16(7), []byte{2, 4}, byte(3), false)
+	f.Add(uint16(0), []byte{}, byte(0), false)
+	f.Add(uint16(999), []byte{9, 9}, byte(2), true)
+
+	f.Fuzz(func(t *testing.T, items uint16, sizes []byte, objective byte, stocked bool) {
+		p := fuzzProblem(items, sizes, objective, stocked)
+		result, err := Solve(context.Background(), p)
+
+		if len(p.Packs) == 0 {
+			if !errors.Is(err, ErrNoPacking) {
+				t.Fatalf("expected no packing error without packs, got %v", err)
+			}
+			return
+		}
+		if validatePacks(p.Packs) != nil {
+			if !errors.Is(err, ErrInvalidPack) {
+				t.Fatalf("expected invalid pack error for %+v, got %v", p.Packs, err)
+			}
+			return
+		}
+
+		table := bruteForce(p.Packs, p.Items+largestPack(p.Packs)*2, p.Objective == LeastCost)
+		expected, ok := expectedKey(p, table)
+		if !ok {
+			if !errors.Is(err, ErrNoPacking) {
+				t.Fatalf("expected no packing for %+v, got %+v and %v", p, result, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("expected a packing of %+v, got %v", p, err)
+		}
+
+		checkResult(t, p, result)
+		got := rankings[p.Objective].key(candidate{overshoot: result.Overshoot, count: result.PackCount,
+			cost: result.Cost})
+		if !slices.Equal(got, expected) {
+			t.Fatalf("%+v: expected a packing ranked by %v, got %+v", p, expected, result)
+		}
+
+		if p.Objective == LeastOvershoot && !stocked {
+			solver, err := NewOvershootSolver(context.Background(), packSizesOf(p.Packs), 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if overshoot, packCount := solver.Pack(p.Items); overshoot != result.Overshoot ||
+				packCount != result.PackCount {
+				t.Fatalf("%+v: expected the solver to pack %d over in %d packs, got %d in %d", p,
+					result.Overshoot, result.PackCount, overshoot, packCount)
+			}
+		}
+	})
+}
+
+// checkResult checks that the result adds up and only uses packs of the problem in stock
+func checkResult(t *testing.T, p Problem, result Result) {
+	t.Helper()
+
+	total, count, cost := 0, 0, 0
+	for _, pc := range result.Packs {
+		i := slices.IndexFunc(p.Packs, func(pack Pack) bool { return pack.Size == pc.Size })
+		if i < 0 || pc.Count < 1 || (p.Packs[i].Stock != nil && pc.Count > *p.Packs[i].Stock) {
+			t.Fatalf("%+v: %d packs of %d items cannot be used", p, pc.Count, pc.Size)
+		}
+		total += pc.Size * pc.Count
+		count += pc.Count
+		cost += p.Packs[i].Cost * pc.Count
+	}
+	if total != result.TotalItems || count != result.PackCount || cost != result.Cost ||
+		result.Overshoot != total-p.Items || result.Overshoot < 0 {
+		t.Fatalf("%+v: the result does not add up: %+v", p, result)
+	}
+}
+
+func packSizesOf(packs []Pack) []int {
+	sizes := make([]int, 0, len(packs))
+	for _, p := range packs {
+		sizes = append(sizes, p.Size)
+	}
+
+	return sizes
+}
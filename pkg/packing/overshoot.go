@@ -0,0 +1,101 @@
+package packing
+
+import (
+	"context"
+	"fmt"
+)
+
+// OvershootSolver packs any number of items with the least overshoot, then
+// the fewest packs, as Solve does for LeastOvershoot, from one table of the
+// pack sizes. It packs many quantities with the same pack sizes faster than
+// Solve, and reports which quantities the pack sizes ship exactly.
+//
+// Every total the packs reach is a multiple of the gcd of their sizes, so the
+// packs are measured in units of the gcd and each quantity rounded up to a
+// whole unit, which ships the same packs in a smaller table. The table covers
+// the rest of any quantity once its bulk packs are taken out, and the least
+// overshoot of a quantity is the first total the packs reach from its rest.
+type OvershootSolver struct {
+	unit   int
+	bulk   Pack
+	others int
+	table  packTable
+	// next[x] is the first total reached from x
+	next []int
+}
+
+// NewOvershootSolver builds the table of the pack sizes. It fails with
+// ErrLimitExceeded instead of checking maxTableSize totals or more, unless
+// maxTableSize is zero.
+func NewOvershootSolver(ctx context.Context, packSizes []int, maxTableSize int) (*OvershootSolver, error) {
+	if len(packSizes) == 0 {
+		return nil, fmt.Errorf("%w: no pack sizes", ErrNoPacking)
+	}
+
+	s := &OvershootSolver{}
+	packs := make([]Pack, 0, len(packSizes))
+	for _, size := range packSizes {
+		packs = append(packs, Pack{Size: size})
+		s.unit = gcd(s.unit, size)
+	}
+	if err := validatePacks(packs); err != nil {
+		return nil, err
+	}
+	for i := range packs {
+		packs[i].Size /= s.unit
+	}
+
+	// the rest of a quantity is below others plus a bulk pack, and the packs
+	// reach a total less than a bulk pack past it
+	s.bulk, s.others = bulkPack(packs, false)
+	maxCheck := s.others + 2*s.bulk.Size
+	table, err := newPackTable(ctx, packs, maxCheck, false, maxTableSize)
+	if err != nil {
+		return nil, err
+	}
+	s.table = table
+	s.next = make([]int, maxCheck+1)
+	for x, reached := maxCheck, maxCheck; x >= 0; x-- {
+		if _, _, ok := table.best(x); ok {
+			reached = x
+		}
+		s.next[x] = reached
+	}
+
+	return s, nil
+}
+
+// Pack returns the overshoot and number of packs of the best packing of
+// items, items must not be negative
+func (s *OvershootSolver) Pack(items int) (overshoot, packCount int) {
+	units := (items + s.unit - 1) / s.unit
+	count := bulkCount(s.bulk, s.others, units)
+	total := s.next[units-count*s.bulk.Size]
+	packCount, _, _ = s.table.best(total)
+
+	return (total+count*s.bulk.Size)*s.unit - items, packCount + count
+}
+
+// GCD returns the gcd of the pack sizes, only its multiples ship exactly
+func (s *OvershootSolver) GCD() int {
+	return s.unit
+}
+
+// Frobenius returns the largest multiple of the gcd of the pack sizes that
+// the packs cannot ship exactly, or -1 when they ship every multiple. Past the
+// bulk packs a total is reached when its rest is, so the largest total not
+// reached is in the table.
+func (s *OvershootSolver) Frobenius() int {
+	for x := s.others + s.bulk.Size; x > 0; x-- {
+		if _, _, ok := s.table.best(x); !ok {
+			return x * s.unit
+		}
+	}
+
+	return -1
+}
+
+// TableSize returns the number of totals in the table of the solver
+func (s *OvershootSolver) TableSize() int {
+	return len(s.next)
+}
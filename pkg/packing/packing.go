@@ -0,0 +1,200 @@
+// Package packing finds the packs that ship a number of items.
+//
+// A Problem has the items to ship, the packs that can ship them and the
+// Objective the packings are ranked by. Solve returns the best packing and
+// Rank the best few, each as a Result with its packs, overshoot and counts.
+// Packs can be priced and have a limited stock. OvershootSolver packs many
+// quantities with the same pack sizes faster than Solve, e.g. to compare sets
+// of pack sizes.
+//
+// The solver checks every total of items up to the order, less the bulk
+// packs it is sure to use, so its work depends on the pack sizes and not on the
+// size of the order. A stocked bulk pack is only taken out of the order up to
+// its stock, less a margin of the largest size over the gcd of the sizes, and
+// the next pack ships the bulk of the order when it runs out, so stock adds at
+// most that margin of each stocked pack to the totals checked. MaxTableSize
+// bounds the work for pack sizes that would need a large table, and the
+// context stops it.
+package packing
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Objective is what the packings of a problem are ranked by
+type Objective string
+
+// objectives of a problem
+const (
+	// LeastOvershoot ships the fewest extra items, then uses the fewest packs
+	LeastOvershoot Objective = "least_overshoot"
+	// FewestPacks uses the fewest packs, then ships the fewest extra items
+	FewestPacks Objective = "fewest_packs"
+	// LeastCost has the lowest total pack cost, then ships the fewest extra
+	// items and uses the fewest packs
+	LeastCost Objective = "least_cost"
+	// Exact only ships exactly the number of items ordered, with the fewest packs
+	Exact Objective = "exact"
+)
+
+var (
+	// ErrInvalidPack is returned when a pack has no size, a negative cost
+	// or stock, or the same size as another pack
+	ErrInvalidPack = errors.New("invalid pack")
+	// ErrInvalidItems is returned when the number of items is negative
+	ErrInvalidItems = errors.New("invalid number of items")
+	// ErrUnknownObjective is returned when an objective is not recognised
+	ErrUnknownObjective = errors.New("unknown packing objective")
+	// ErrNoPacking is returned when no packing meets the problem, e.g. there
+	// are no packs, not enough stock or the items can't be packed exactly
+	ErrNoPacking = errors.New("no packing found for the order")
+	// ErrLimitExceeded is returned when packing would check more totals than
+	// the MaxTableSize of the problem
+	ErrLimitExceeded = errors.New("packing the order exceeds the computation limits")
+)
+
+// Pack is a pack size that can ship items and what it costs to ship. Stock
+// is the number of packs available, it is nil when the supply is unlimited.
+type Pack struct {
+	Size  int
+	Cost  int
+	Stock *int
+}
+
+// Problem is a number of items to ship with packs. Objective defaults to
+// LeastOvershoot. MaxTableSize is the most totals the solver can check, it
+// bounds the memory used, zero is no limit. The totals checked depend on the
+// pack sizes and, for stocked packs, on the margin kept back from their stock,
+// but not on Items.
+type Problem struct {
+	Items        int
+	Packs        []Pack
+	Objective    Objective
+	MaxTableSize int
+}
+
+// PackCount is a number of packs of one size
+type PackCount struct {
+	Size  int `json:"size"`
+	Count int `json:"count"`
+}
+
+// Result is a packing of the items of a problem, largest pack first.
+// Overshoot is the items shipped beyond the items of the problem.
+type Result struct {
+	Packs      []PackCount `json:"packs"`
+	TotalItems int         `json:"total_items"`
+	Overshoot  int         `json:"overshoot"`
+	PackCount  int         `json:"pack_count"`
+	Cost       int         `json:"cost"`
+}
+
+// Counts returns the number of packs of each size of the result
+func (r Result) Counts() map[int]int {
+	counts := make(map[int]int, len(r.Packs))
+	for _, p := range r.Packs {
+		counts[p.Size] = p.Count
+	}
+
+	return counts
+}
+
+// Ranking is the best packings of a problem, best first. Criteria are what
+// the objective ranks packings by in order, and Reason is why the first
+// packing ranks before the second, e.g. "same overshoot, fewer packs".
+type Ranking struct {
+	Results  []Result
+	Criteria []string
+	Reason   string
+}
+
+// Solve returns the best packing of the problem for its objective
+func Solve(ctx context.Context, p Problem) (Result, error) {
+	ranking, err := Rank(ctx, p, 1)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return ranking.Results[0], nil
+}
+
+// Rank returns the best k packings of the problem in the order of its
+// objective, each the best packing of its total of items. It returns fewer
+// when there are fewer totals the packs can ship.
+func Rank(ctx context.Context, p Problem, k int) (*Ranking, error) {
+	r, err := p.ranking()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	return rankPackings(ctx, r, p, max(k, 1))
+}
+
+func (p Problem) ranking() (ranking, error) {
+	objective := p.Objective
+	if objective == "" {
+		objective = LeastOvershoot
+	}
+
+	r, ok := rankings[objective]
+	if !ok {
+		return ranking{}, fmt.Errorf("%w: %s", ErrUnknownObjective, objective)
+	}
+
+	return r, nil
+}
+
+func (p Problem) validate() error {
+	if p.Items < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidItems, p.Items)
+	}
+	if len(p.Packs) == 0 {
+		return fmt.Errorf("%w: no packs to ship %d items", ErrNoPacking, p.Items)
+	}
+
+	return validatePacks(p.Packs)
+}
+
+func validatePacks(packs []Pack) error {
+	sizes := make([]int, 0, len(packs))
+	for _, pack := range packs {
+		if pack.Size < 1 || pack.Cost < 0 || (pack.Stock != nil && *pack.Stock < 0) {
+			return fmt.Errorf("%w: %d items", ErrInvalidPack, pack.Size)
+		}
+		sizes = append(sizes, pack.Size)
+	}
+	slices.Sort(sizes)
+	for i := 1; i < len(sizes); i++ {
+		if sizes[i] == sizes[i-1] {
+			return fmt.Errorf("%w: two packs of %d items", ErrInvalidPack, sizes[i])
+		}
+	}
+
+	return nil
+}
+
+// newResult summarises a packing of items, largest pack first
+func newResult(items int, packs []Pack, counts map[int]int) Result {
+	result := Result{Packs: make([]PackCount, 0, len(counts))}
+	for _, pack := range packs {
+		count := counts[pack.Size]
+		if count == 0 {
+			continue
+		}
+		result.Packs = append(result.Packs, PackCount{Size: pack.Size, Count: count})
+		result.TotalItems += pack.Size * count
+		result.PackCount += count
+		result.Cost += pack.Cost * count
+	}
+	slices.SortFunc(result.Packs, func(a, b PackCount) int { return cmp.Compare(b.Size, a.Size) })
+	result.Overshoot = result.TotalItems - items
+
+	return result
+}
@@ -0,0 +1,181 @@
+package packing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	defaultPacks := sizedPacks([]int{5000, 2000, 1000, 500, 250})
+	cheapSmallPacks := []Pack{{Size: 3, Cost: 1}, {Size: 5, Cost: 10}}
+
+	testcases := []struct {
+		name           string
+		problem        Problem
+		expectedCounts map[int]int
+		expectedErr    error
+	}{
+		{
+			name:           "least overshoot by default",
+			problem:        Problem{Items: 501, Packs: defaultPacks},
+			expectedCounts: map[int]int{500: 1, 250: 1},
+		},
+		{
+			name:           "least overshoot of a large order",
+			problem:        Problem{Items: 500000, Packs: sizedPacks([]int{23, 31, 53}), Objective: LeastOvershoot},
+			expectedCounts: map[int]int{23: 2, 31: 7, 53: 9429},
+		},
+		{
+			name:           "fewest packs",
+			problem:        Problem{Items: 501, Packs: defaultPacks, Objective: FewestPacks},
+			expectedCounts: map[int]int{1000: 1},
+		},
+		{
+			name:           "least cost",
+			problem:        Problem{Items: 10, Packs: cheapSmallPacks, Objective: LeastCost},
+			expectedCounts: map[int]int{3: 4},
+		},
+		{
+			name:           "exact",
+			problem:        Problem{Items: 11, Packs: cheapSmallPacks, Objective: Exact},
+			expectedCounts: map[int]int{3: 2, 5: 1},
+		},
+		{
+			name:           "nothing to ship",
+			problem:        Problem{Items: 0, Packs: defaultPacks},
+			expectedCounts: map[int]int{},
+		},
+		{
+			name:        "not exact",
+			problem:     Problem{Items: 7, Packs: cheapSmallPacks, Objective: Exact},
+			expectedErr: ErrNoPacking,
+		},
+		{
+			name: "out of stock",
+			problem: Problem{Items: 1001,
+				Packs: stockedPacks(defaultPacks, map[int]int{250: 0, 500: 1, 1000: 0, 2000: 0, 5000: 0})},
+			expectedErr: ErrNoPacking,
+		},
+		{
+			name:        "no packs",
+			problem:     Problem{Items: 1},
+			expectedErr: ErrNoPacking,
+		},
+		{
+			name:        "negative items",
+			problem:     Problem{Items: -1, Packs: defaultPacks},
+			expectedErr: ErrInvalidItems,
+		},
+		{
+			name:        "pack without a size",
+			problem:     Problem{Items: 1, Packs: sizedPacks([]int{0, 250})},
+			expectedErr: ErrInvalidPack,
+		},
+		{
+			name:        "negative cost",
+			problem:     Problem{Items: 1, Packs: []Pack{{Size: 250, Cost: -1}}},
+			expectedErr: ErrInvalidPack,
+		},
+		{
+			name:        "same size twice",
+			problem:     Problem{Items: 1, Packs: sizedPacks([]int{250, 500, 250})},
+			expectedErr: ErrInvalidPack,
+		},
+		{
+			name:        "unknown objective",
+			problem:     Problem{Items: 1, Packs: defaultPacks, Objective: "cheapest"},
+			expectedErr: ErrUnknownObjective,
+		},
+		{
+			name:        "table limit",
+			problem:     Problem{Items: 1, Packs: sizedPacks([]int{9999, 10000}), MaxTableSize: 1000},
+			expectedErr: ErrLimitExceeded,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Solve(context.Background(), tc.problem)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v but got %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if !maps.Equal(result.Counts(), tc.expectedCounts) {
+				t.Errorf("expected %v but got %v", tc.expectedCounts, result.Counts())
+			}
+		})
+	}
+}
+
+func TestSolveResult(t *testing.T) {
+	packs := []Pack{{Size: 250, Cost: 3}, {Size: 500, Cost: 5}, {Size: 1000, Cost: 9}}
+	result, err := Solve(context.Background(), Problem{Items: 1251, Packs: packs})
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	expected := Result{Packs: []PackCount{{Size: 1000, Count: 1}, {Size: 500, Count: 1}}, TotalItems: 1500,
+		Overshoot: 249, PackCount: 2, Cost: 14}
+	if fmt.Sprint(result) != fmt.Sprint(expected) {
+		t.Errorf("expected %+v but got %+v", expected, result)
+	}
+}
+
+func TestRank(t *testing.T) {
+	packs := sizedPacks([]int{5000, 2000, 1000, 500, 250})
+	ranking, err := Rank(context.Background(), Problem{Items: 501, Packs: packs}, 3)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	expectedTotals := []int{750, 1000, 1250}
+	if len(ranking.Results) != len(expectedTotals) {
+		t.Fatalf("expected %d packings but got %+v", len(expectedTotals), ranking.Results)
+	}
+	for i, total := range expectedTotals {
+		if ranking.Results[i].TotalItems != total || ranking.Results[i].Overshoot != total-501 {
+			t.Errorf("expected packing %d to ship %d items but got %+v", i, total, ranking.Results[i])
+		}
+	}
+	if fmt.Sprint(ranking.Criteria) != "[overshoot pack count]" || ranking.Reason != "lower overshoot" {
+		t.Errorf("expected the overshoot criteria and reason but got %v and %q", ranking.Criteria, ranking.Reason)
+	}
+
+	ranking, err = Rank(context.Background(), Problem{Items: 11, Packs: sizedPacks([]int{3, 5}), Objective: Exact}, 5)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if len(ranking.Results) != 1 || ranking.Reason != "only packing found" {
+		t.Errorf("expected the only exact packing but got %+v", ranking)
+	}
+}
+
+func TestSolveCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Solve(ctx, Problem{Items: 1, Packs: sizedPacks([]int{999_983, 1_000_000})})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error but got %v", err)
+	}
+}
+
+func TestOvershootSolver(t *testing.T) {
+	_, err := NewOvershootSolver(context.Background(), []int{250, -500}, 0)
+	if !errors.Is(err, ErrInvalidPack) {
+		t.Errorf("expected invalid pack error but got %v", err)
+	}
+	_, err = NewOvershootSolver(context.Background(), nil, 0)
+	if !errors.Is(err, ErrNoPacking) {
+		t.Errorf("expected no packing error but got %v", err)
+	}
+	_, err = NewOvershootSolver(context.Background(), []int{9999, 10000}, 1000)
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected limit error but got %v", err)
+	}
+}
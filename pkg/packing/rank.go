@@ -0,0 +1,137 @@
+package packing
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// criterion is a measure packings are ranked by, lower values rank first
+type criterion struct {
+	name   string
+	better string
+	value  func(c candidate) int
+}
+
+var (
+	overshootCriterion = criterion{name: "overshoot", better: "lower overshoot",
+		value: func(c candidate) int { return c.overshoot }}
+	countCriterion = criterion{name: "pack count", better: "fewer packs",
+		value: func(c candidate) int { return c.count }}
+	costCriterion = criterion{name: "cost", better: "lower cost",
+		value: func(c candidate) int { return c.cost }}
+)
+
+// ranking is how an objective searches the packings of a problem and orders them
+type ranking struct {
+	// byCost fills the table with the cheapest packs reaching each total
+	// instead of the fewest
+	byCost bool
+	// exact only accepts packings of exactly the number of items ordered
+	exact    bool
+	criteria []criterion
+}
+
+// rankings are how each objective searches the packings of a problem and orders them
+var rankings = map[Objective]ranking{
+	LeastOvershoot: {criteria: []criterion{overshootCriterion, countCriterion}},
+	FewestPacks:    {criteria: []criterion{countCriterion, overshootCriterion}},
+	LeastCost:      {byCost: true, criteria: []criterion{costCriterion, overshootCriterion, countCriterion}},
+	Exact:          {exact: true, criteria: []criterion{countCriterion}},
+}
+
+// rankPackings returns the best k packings of the problem in the order of
+// the ranking, each the best packing of its total of items. A packing that
+// ships a full largest pack or more extra items always has a pack it could
+// drop, so only totals below the items plus the largest pack are checked.
+func rankPackings(ctx context.Context, r ranking, p Problem, k int) (*Ranking, error) {
+	// every candidate has the same bulk packs, so they rank the same without them
+	bulk, packs := splitOrder(p.Packs, p.Items, r.byCost)
+	rest := p.Items
+	for size, count := range bulk {
+		rest -= size * count
+	}
+	if !fits(packs, rest) {
+		return nil, fmt.Errorf("%w: not enough packs in stock for %d items", ErrNoPacking, p.Items)
+	}
+	maxCheck := rest
+	if !r.exact {
+		maxCheck += largestPack(p.Packs) - 1
+	}
+	table, err := newPackTable(ctx, packs, maxCheck, r.byCost, p.MaxTableSize)
+	if err != nil {
+		return nil, err
+	}
+
+	top := topCandidates(table, rest, maxCheck, r.compare, k)
+	if len(top) == 0 && r.exact {
+		return nil, fmt.Errorf("%w: %d items cannot be packed exactly", ErrNoPacking, p.Items)
+	}
+	if len(top) == 0 {
+		return nil, fmt.Errorf("%w: not enough packs in stock for %d items", ErrNoPacking, p.Items)
+	}
+
+	ranked := &Ranking{Reason: "only packing found"}
+	for _, c := range r.criteria {
+		ranked.Criteria = append(ranked.Criteria, c.name)
+	}
+	for _, c := range top {
+		counts := withBulkPacks(table.packs(c.sum), bulk)
+		ranked.Results = append(ranked.Results, newResult(p.Items, p.Packs, counts))
+	}
+	if len(top) > 1 {
+		ranked.Reason = r.reason(top[0], top[1])
+	}
+
+	return ranked, nil
+}
+
+// compare orders packings by the first criterion they differ on
+func (r ranking) compare(a, b candidate) int {
+	for _, c := range r.criteria {
+		if n := cmp.Compare(c.value(a), c.value(b)); n != 0 {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// reason describes why a ranks before b, e.g. "same overshoot, fewer packs"
+func (r ranking) reason(a, b candidate) string {
+	var reasons []string
+	for _, c := range r.criteria {
+		if c.value(a) != c.value(b) {
+			return strings.Join(append(reasons, c.better), ", ")
+		}
+		reasons = append(reasons, "same "+c.name)
+	}
+
+	return strings.Join(append(reasons, "fewer items"), ", ")
+}
+
+// withBulkPacks adds the bulk packs to the packs found for the rest of an order
+func withBulkPacks(packCount map[int]int, bulk map[int]int) map[int]int {
+	for size, count := range bulk {
+		if count > 0 {
+			packCount[size] += count
+		}
+	}
+
+	return packCount
+}
+
+// fits reports whether the packs in stock can hold items, packs without a
+// stock hold any number of items
+func fits(packs []Pack, items int) bool {
+	capacity := 0
+	for _, p := range packs {
+		if p.Stock == nil {
+			return true
+		}
+		capacity += *p.Stock * p.Size
+	}
+
+	return capacity >= items
+}
@@ -0,0 +1,338 @@
+package packing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// cancelCheckInterval is how many totals are added to a table between checks
+// that the packing was not cancelled
+const cancelCheckInterval = 1 << 14
+
+type dpEntry struct {
+	count int
+	cost  int
+	prev  int
+	pack  int
+}
+
+// candidate is a reachable total of items and the best packs found to reach it
+type candidate struct {
+	sum       int
+	overshoot int
+	count     int
+	cost      int
+}
+
+// packTable holds the best packs found to reach every total up to a limit
+type packTable interface {
+	// best returns the pack count and cost of the best packs reaching sum,
+	// ok is false when sum cannot be reached
+	best(sum int) (count, cost int, ok bool)
+	// packs returns the number of packs of each size used to reach sum
+	packs(sum int) map[int]int
+}
+
+// newPackTable finds, for every total up to maxCheck, the packs reaching it
+// with the fewest packs, or the lowest cost then fewest packs when byCost is set.
+// Packs with a stock are never used more times than there are in stock.
+// It fails with ErrLimitExceeded instead of checking limit totals or more,
+// unless limit is zero, and stops and returns the context error when ctx is done.
+func newPackTable(ctx context.Context, packs []Pack, maxCheck int, byCost bool, limit int) (packTable, error) {
+	if limit > 0 && maxCheck >= limit {
+		return nil, fmt.Errorf("%w: %d totals to check, at most %d", ErrLimitExceeded, maxCheck+1, limit)
+	}
+
+	if slices.ContainsFunc(packs, func(p Pack) bool { return p.Stock != nil }) {
+		return buildBoundedTable(ctx, packs, maxCheck, byCost)
+	}
+
+	return buildPackTable(ctx, packs, maxCheck, byCost)
+}
+
+// better reports whether reaching a total with count packs costing cost
+// improves on the entry found so far
+func (e dpEntry) better(count, cost int, byCost bool) bool {
+	if e.count == -1 {
+		return true
+	}
+	if byCost && e.cost != cost {
+		return e.cost > cost
+	}
+
+	return e.count > count
+}
+
+// unboundedTable is the table of packs when there is an unlimited supply of every pack
+type unboundedTable []dpEntry
+
+func buildPackTable(ctx context.Context, packs []Pack, maxCheck int, byCost bool) (unboundedTable, error) {
+	dp := make(unboundedTable, maxCheck+1)
+	for i := range dp {
+		dp[i].count = -1
+	}
+	dp[0].count = 0
+
+	for x := 0; x <= maxCheck; x++ {
+		if x%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if dp[x].count == -1 {
+			continue
+		}
+		for _, p := range packs {
+			next := x + p.Size
+			if next > maxCheck {
+				continue
+			}
+
+			count, cost := dp[x].count+1, dp[x].cost+p.Cost
+			if dp[next].better(count, cost, byCost) {
+				dp[next] = dpEntry{count: count, cost: cost, prev: x, pack: p.Size}
+			}
+		}
+	}
+
+	return dp, nil
+}
+
+func (dp unboundedTable) best(sum int) (int, int, bool) {
+	if sum >= len(dp) || dp[sum].count == -1 {
+		return 0, 0, false
+	}
+
+	return dp[sum].count, dp[sum].cost, true
+}
+
+func (dp unboundedTable) packs(sum int) map[int]int {
+	packCount := make(map[int]int)
+	current := sum
+	for current > 0 {
+		entry := dp[current]
+		packCount[entry.pack]++
+		current = entry.prev
+	}
+
+	return packCount
+}
+
+// bundle is a number of packs of one size the bounded table takes together
+type bundle struct {
+	size  int
+	count int
+	cost  int
+}
+
+// boundedTable is the table of packs when the supply of some packs is limited.
+// Each pack is split in bundles of 1, 2, 4... packs so any number of packs up
+// to the stock is a sum of bundles, and each bundle is used at most once.
+type boundedTable struct {
+	dp      []dpEntry
+	bundles []bundle
+	// taken[i] has a bit set for every total where bundle i is in the best packs
+	taken [][]uint64
+}
+
+func buildBoundedTable(ctx context.Context, packs []Pack, maxCheck int, byCost bool) (*boundedTable, error) {
+	t := &boundedTable{dp: make([]dpEntry, maxCheck+1)}
+	for i := range t.dp {
+		t.dp[i].count = -1
+	}
+	t.dp[0].count = 0
+
+	for _, p := range packs {
+		// more than this many packs of one size can't be needed to reach maxCheck
+		limit := maxCheck/p.Size + 1
+		if p.Stock != nil {
+			limit = min(limit, *p.Stock)
+		}
+		for n := 1; limit > 0; n *= 2 {
+			n = min(n, limit)
+			t.bundles = append(t.bundles, bundle{size: p.Size * n, count: n, cost: p.Cost * n})
+			limit -= n
+		}
+	}
+
+	t.taken = make([][]uint64, len(t.bundles))
+	for i, b := range t.bundles {
+		t.taken[i] = make([]uint64, maxCheck/64+1)
+		for x := maxCheck; x >= b.size; x-- {
+			if x%cancelCheckInterval == 0 && ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			from := t.dp[x-b.size]
+			if from.count == -1 {
+				continue
+			}
+
+			count, cost := from.count+b.count, from.cost+b.cost
+			if t.dp[x].better(count, cost, byCost) {
+				t.dp[x] = dpEntry{count: count, cost: cost}
+				t.taken[i][x/64] |= 1 << (x % 64)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+func (t *boundedTable) best(sum int) (int, int, bool) {
+	if sum >= len(t.dp) || t.dp[sum].count == -1 {
+		return 0, 0, false
+	}
+
+	return t.dp[sum].count, t.dp[sum].cost, true
+}
+
+func (t *boundedTable) packs(sum int) map[int]int {
+	packCount := make(map[int]int)
+	current := sum
+	for i := len(t.bundles) - 1; i >= 0 && current > 0; i-- {
+		if t.taken[i][current/64]&(1<<(current%64)) != 0 {
+			b := t.bundles[i]
+			packCount[b.size/b.count] += b.count
+			current -= b.size
+		}
+	}
+
+	return packCount
+}
+
+// topCandidates returns the k reachable totals between N and maxCheck that
+// compare first, in order. Totals that compare equal keep the smaller total first.
+func topCandidates(table packTable, N, maxCheck int, compare func(a, b candidate) int, k int) []candidate {
+	top := make([]candidate, 0, k+1)
+	for x := N; x <= maxCheck; x++ {
+		count, cost, ok := table.best(x)
+		if !ok {
+			continue
+		}
+
+		c := candidate{sum: x, overshoot: x - N, count: count, cost: cost}
+		i := slices.IndexFunc(top, func(t candidate) bool { return compare(c, t) < 0 })
+		if i < 0 {
+			i = len(top)
+		}
+		if i < k {
+			top = slices.Insert(top, i, c)
+			top = top[:min(len(top), k)]
+		}
+	}
+
+	return top
+}
+
+// splitOrder picks the bulk packs a best packing of numberOfItems is sure to
+// use, so only the rest of the order has to be packed with a table. It returns
+// the number of bulk packs of each size and the packs for the rest, with their
+// stock less the bulk packs taken.
+//
+// Packs are taken best first, as bulkPack ranks them, until an unlimited pack
+// or one with enough stock for the order. A stocked pack keeps a margin of
+// largest/g packs, g being the gcd of the pack sizes, so any packs swapped for
+// it as bulkPack describes are still in stock, and when it runs out the next
+// pack ships the bulk of the order. Only the packs kept back from a stocked
+// bulk pack are added to the rest, so the table still depends on the pack
+// sizes and not on the size of the order.
+func splitOrder(packs []Pack, numberOfItems int, byCost bool) (map[int]int, []Pack) {
+	ordered := slices.SortedFunc(slices.Values(packs), func(a, b Pack) int { return bulkOrder(b, a, byCost) })
+	g := 0
+	for _, p := range packs {
+		g = gcd(g, p.Size)
+	}
+	margin := largestPack(packs) / g
+
+	bulk := make(map[int]int)
+	rest := numberOfItems
+	// better is the most items the packs kept back from the bulk packs taken hold
+	better := 0
+	for i, p := range ordered {
+		largestOther := 0
+		for _, other := range ordered[i+1:] {
+			largestOther = max(largestOther, other.Size)
+		}
+		count := bulkCount(p, (p.Size/g-1)*largestOther+better, rest)
+		if p.Stock == nil {
+			bulk[p.Size] = count
+			rest -= count * p.Size
+			break
+		}
+
+		taken := max(0, min(count, *p.Stock)-margin)
+		bulk[p.Size] = taken
+		rest -= taken * p.Size
+		if *p.Stock >= count {
+			break
+		}
+		better += (*p.Stock - taken) * p.Size
+	}
+
+	remaining := make([]Pack, len(packs))
+	for i, p := range packs {
+		remaining[i] = p
+		if p.Stock != nil && bulk[p.Size] > 0 {
+			stock := *p.Stock - bulk[p.Size]
+			remaining[i].Stock = &stock
+		}
+	}
+
+	return bulk, remaining
+}
+
+// bulkOrder orders packs by how well they ship the bulk of an order, by the
+// lowest cost per item when byCost is set, then by the largest size
+func bulkOrder(a, b Pack, byCost bool) int {
+	if byCost && a.Cost*b.Size != b.Cost*a.Size {
+		// a lower cost per item ranks higher
+		return b.Cost*a.Size - a.Cost*b.Size
+	}
+
+	return a.Size - b.Size
+}
+
+// bulkPack returns the best pack of unlimited packs to ship the bulk of an
+// order and the most items the other packs of a best packing hold.
+//
+// Among any bulk/g other packs, g being the gcd of the pack sizes, some add
+// up to a multiple of the bulk pack, and swapping them for bulk packs ships
+// the same items cheaper or in fewer packs. A best packing then has fewer than
+// bulk/g other packs, and all the items past what they can hold go in bulk
+// packs. The table only covers the rest, so its size depends on the pack sizes
+// and not on the size of the order.
+func bulkPack(packs []Pack, byCost bool) (Pack, int) {
+	bulk := slices.MaxFunc(packs, func(a, b Pack) int { return bulkOrder(a, b, byCost) })
+
+	g, largestOther := 0, 0
+	for _, p := range packs {
+		g = gcd(g, p.Size)
+		if p.Size != bulk.Size {
+			largestOther = max(largestOther, p.Size)
+		}
+	}
+
+	return bulk, (bulk.Size/g - 1) * largestOther
+}
+
+// bulkCount returns how many bulk packs a best packing of numberOfItems is
+// sure to use when the other packs hold at most others items
+func bulkCount(bulk Pack, others, numberOfItems int) int {
+	if numberOfItems <= others {
+		return 0
+	}
+
+	return (numberOfItems - others) / bulk.Size
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// largestPack returns the size of the largest pack
+func largestPack(packs []Pack) int {
+	return slices.MaxFunc(packs, func(a, b Pack) int { return a.Size - b.Size }).Size
+}
@@ -0,0 +1,162 @@
+package packing
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+// sizedPacks returns free packs of the given sizes with unlimited stock
+func sizedPacks(sizes []int) []Pack {
+	packs := make([]Pack, 0, len(sizes))
+	for _, size := range sizes {
+		packs = append(packs, Pack{Size: size})
+	}
+
+	return packs
+}
+
+// stockedPacks returns a copy of packs with the given stock for some sizes
+func stockedPacks(packs []Pack, stock map[int]int) []Pack {
+	stocked := make([]Pack, len(packs))
+	for i, p := range packs {
+		stocked[i] = p
+		if n, ok := stock[p.Size]; ok {
+			stocked[i].Stock = &n
+		}
+	}
+
+	return stocked
+}
+
+func TestBoundedTableMatchesUnlimitedStock(t *testing.T) {
+	packs := []Pack{{Size: 23, Cost: 4}, {Size: 31, Cost: 5}, {Size: 53, Cost: 9}}
+	plenty := stockedPacks(packs, map[int]int{23: 1000, 31: 1000, 53: 1000})
+
+	for _, byCost := range []bool{false, true} {
+		unbounded, err := newPackTable(context.Background(), packs, 2000, byCost, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		bounded, err := newPackTable(context.Background(), plenty, 2000, byCost, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for sum := 0; sum <= 2000; sum++ {
+			count, cost, ok := unbounded.best(sum)
+			boundedCount, boundedCost, boundedOK := bounded.best(sum)
+			if ok != boundedOK || count != boundedCount || cost != boundedCost {
+				t.Fatalf("sum %d by cost %v: expected (%d, %d, %v), got (%d, %d, %v)",
+					sum, byCost, count, cost, ok, boundedCount, boundedCost, boundedOK)
+			}
+			if !ok {
+				continue
+			}
+
+			total := 0
+			for size, n := range bounded.packs(sum) {
+				total += size * n
+			}
+			if total != sum {
+				t.Fatalf("sum %d: packs add up to %d", sum, total)
+			}
+		}
+	}
+}
+
+func TestSplitOrderMatchesFullTable(t *testing.T) { //nolint:cyclop
+	packSets := map[string][]Pack{
+		"default":       sizedPacks([]int{5000, 2000, 1000, 500, 250}),
+		"coprime":       sizedPacks([]int{23, 31, 53}),
+		"priced":        {{Size: 23, Cost: 4}, {Size: 31, Cost: 5}, {Size: 53, Cost: 9}},
+		"same cost":     {{Size: 6, Cost: 2}, {Size: 9, Cost: 3}, {Size: 10, Cost: 4}},
+		"bulk runs out": stockedPacks(sizedPacks([]int{5000, 2000, 1000, 500, 250}), map[int]int{5000: 3}),
+		"bulk in stock": stockedPacks(sizedPacks([]int{23, 31, 53}), map[int]int{53: 700}),
+		"all stocked": stockedPacks([]Pack{{Size: 23, Cost: 4}, {Size: 31, Cost: 5}, {Size: 53, Cost: 9}},
+			map[int]int{23: 40, 31: 300, 53: 500}),
+	}
+
+	const maxItems = 45000
+	for name, packs := range packSets {
+		for objective, r := range rankings {
+			t.Run(name+" "+string(objective), func(t *testing.T) {
+				table := bruteForce(packs, maxItems+largestPack(packs), r.byCost)
+				for n := 1; n < maxItems; n += 293 {
+					p := Problem{Items: n, Packs: packs, Objective: objective}
+					got, err := Solve(context.Background(), p)
+					expected, ok := expectedKey(p, table)
+					if ok != (err == nil) {
+						t.Fatalf("%d items: expected a packing %v, got error %v", n, ok, err)
+					}
+					if err != nil {
+						continue
+					}
+
+					checkResult(t, p, got)
+					key := r.key(candidate{overshoot: got.Overshoot, count: got.PackCount, cost: got.Cost})
+					if !slices.Equal(key, expected) {
+						t.Fatalf("%d items: expected a packing ranked by %v, got %+v", n, expected, got)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSolveLargeStockedOrders(t *testing.T) {
+	packs := sizedPacks([]int{5000, 2000, 1000, 500, 250})
+
+	testcases := []struct {
+		name        string
+		packs       []Pack
+		expected    map[int]int
+		expectedErr error
+	}{
+		{
+			name:     "bulk pack in stock",
+			packs:    stockedPacks(packs, map[int]int{5000: 1_000_000}),
+			expected: map[int]int{5000: 200_000, 250: 1},
+		},
+		{
+			name:     "bulk pack runs out",
+			packs:    stockedPacks(packs, map[int]int{5000: 10}),
+			expected: map[int]int{5000: 10, 2000: 499_975, 250: 1},
+		},
+		{
+			name:     "two bulk packs run out",
+			packs:    stockedPacks(packs, map[int]int{5000: 10, 2000: 5}),
+			expected: map[int]int{5000: 10, 2000: 5, 1000: 999_940, 250: 1},
+		},
+		{
+			name:        "not enough stock",
+			packs:       stockedPacks(packs, map[int]int{5000: 10, 2000: 10, 1000: 10, 500: 10, 250: 10}),
+			expectedErr: ErrNoPacking,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// the table is bounded by the pack sizes, so a small limit fits an order of 1e9 items
+			p := Problem{Items: 1_000_000_001, Packs: tc.packs, MaxTableSize: 1_000_000}
+			result, err := Solve(context.Background(), p)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v but got %v", tc.expectedErr, err)
+			}
+			if err == nil && !maps.Equal(result.Counts(), tc.expected) {
+				t.Errorf("expected %v but got %v", tc.expected, result.Counts())
+			}
+		})
+	}
+}
+
+func BenchmarkSolveStockedBulkPack(b *testing.B) {
+	packs := stockedPacks(sizedPacks([]int{5000, 2000, 1000, 500, 250}), map[int]int{5000: 10})
+	p := Problem{Items: 1_000_000_001, Packs: packs}
+	for range b.N {
+		if _, err := Solve(context.Background(), p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
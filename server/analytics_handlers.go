@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/services"
+)
+
+const (
+	// maxAnalyticsBuckets is the most buckets the packing analytics are split into
+	maxAnalyticsBuckets = 1000
+	// defaultAnalyticsRange is the range of the packing analytics when it does not start at a time
+	defaultAnalyticsRange = 30 * 24 * time.Hour
+	// defaultRecommendationRange is the order history catalogs are recommended for when it does not start at a time
+	defaultRecommendationRange = 365 * 24 * time.Hour
+)
+
+// PackingAnalyticsQuery is the range of the packing analytics, in RFC 3339.
+// To defaults to now and From to 30 days before To. Bucket is day, the
+// default, week or month.
+type PackingAnalyticsQuery struct {
+	From   *time.Time `form:"from"`
+	To     *time.Time `form:"to"`
+	Bucket string     `form:"bucket" binding:"omitempty,oneof=day week month"`
+}
+
+// PackingAnalyticsHandler responds with how efficiently orders were packed over a range of time
+func (s *Server) PackingAnalyticsHandler(c *gin.Context) {
+	var analyticsQuery PackingAnalyticsQuery
+	if err := c.ShouldBindQuery(&analyticsQuery); err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding analytics query: %v", err))
+		badRequest(c, err.Error())
+		return
+	}
+
+	from, to, bucket := analyticsQuery.period()
+	if !from.Before(to) {
+		badRequest(c, "from must be before to")
+		return
+	}
+	if analyticsBuckets(from, to, bucket) > maxAnalyticsBuckets {
+		badRequest(c, fmt.Sprintf("the range has more than %d buckets, use a larger bucket", maxAnalyticsBuckets))
+		return
+	}
+
+	analytics, err := s.db.GetPackingAnalytics(c.Request.Context(), from, to, bucket)
+	switch {
+	case err == nil:
+		ok(c, "successful", analytics)
+	case errors.Is(err, database.ErrInvalidBucket):
+		badRequest(c, err.Error())
+	default:
+		s.logger.Error(fmt.Sprintf("error getting packing analytics: %v", err))
+		internalServerError(c)
+	}
+}
+
+// period returns the range and bucket of the query with their defaults
+func (q *PackingAnalyticsQuery) period() (time.Time, time.Time, string) {
+	to := time.Now()
+	if q.To != nil {
+		to = *q.To
+	}
+	from := to.Add(-defaultAnalyticsRange)
+	if q.From != nil {
+		from = *q.From
+	}
+	bucket := q.Bucket
+	if bucket == "" {
+		bucket = database.BucketDay
+	}
+
+	return from, to, bucket
+}
+
+// analyticsBuckets returns an estimate of the number of buckets of a range
+// from above, buckets are at least 28 days long when they are months
+func analyticsBuckets(from, to time.Time, bucket string) int {
+	length := 24 * time.Hour
+	switch bucket {
+	case database.BucketWeek:
+		length *= 7
+	case database.BucketMonth:
+		length *= 28
+	}
+
+	return int(to.Sub(from)/length) + 2
+}
+
+// CatalogRecommendationsQuery bounds the pack size sets searched for the
+// orders placed from From until To, in RFC 3339. To defaults to now and From to
+// a year before To. The sets have Sizes pack sizes from MinSize to MaxSize,
+// Step apart, and default to sets like the active catalog. Top is the number of
+// sets recommended.
+type CatalogRecommendationsQuery struct {
+	From    *time.Time `form:"from"`
+	To      *time.Time `form:"to"`
+	Sizes   int        `form:"sizes" binding:"omitempty,min=1,max=10"`
+	MinSize int        `form:"min_size" binding:"omitempty,min=1"`
+	MaxSize int        `form:"max_size" binding:"omitempty,min=1"`
+	Step    int        `form:"step" binding:"omitempty,min=1"`
+	Top     int        `form:"top" binding:"omitempty,min=1,max=50"`
+}
+
+// CatalogRecommendationsHandler responds with the sets of pack sizes that
+// would have shipped the order history best
+func (s *Server) CatalogRecommendationsHandler(c *gin.Context) {
+	var recommendationsQuery CatalogRecommendationsQuery
+	if err := c.ShouldBindQuery(&recommendationsQuery); err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding catalog recommendations query: %v", err))
+		badRequest(c, err.Error())
+		return
+	}
+
+	options := recommendationsQuery.options()
+	if !options.From.Before(options.To) {
+		badRequest(c, "from must be before to")
+		return
+	}
+
+	recommendations, err := s.orderService.RecommendCatalogs(c.Request.Context(), options)
+	switch {
+	case err == nil:
+		ok(c, "successful", recommendations)
+	case errors.Is(err, services.ErrInvalidPackSearch):
+		badRequest(c, err.Error())
+	case errors.Is(err, services.ErrNoOrderHistory), errors.Is(err, services.ErrPackingLimit):
+		unprocessableEntity(c, err.Error())
+	case errors.Is(err, context.Canceled):
+		clientClosedRequest(c)
+	default:
+		s.logger.Error(fmt.Sprintf("error recommending pack catalogs: %v", err))
+		internalServerError(c)
+	}
+}
+
+// options returns the search of the query with the default range of the order history
+func (q *CatalogRecommendationsQuery) options() services.RecommendOptions {
+	to := time.Now()
+	if q.To != nil {
+		to = *q.To
+	}
+	from := to.Add(-defaultRecommendationRange)
+	if q.From != nil {
+		from = *q.From
+	}
+
+	return services.RecommendOptions{
+		From:    from,
+		To:      to,
+		Sizes:   q.Sizes,
+		MinSize: q.MinSize,
+		MaxSize: q.MaxSize,
+		Step:    q.Step,
+		Top:     q.Top,
+	}
+}
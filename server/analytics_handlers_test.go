@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/services"
+)
+
+func TestPackingAnalytics(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+	analyticsURL := fmt.Sprintf("http://localhost:%s/analytics/packing", conf.Port)
+
+	createOrder(t, conf, 501)
+	createOrder(t, conf, 251)
+	createOrder(t, conf, 1000)
+	cancelled := createOrder(t, conf, 5000)
+	if resp := transitionOrder(t, conf, cancelled.ID, models.OrderCancelled); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d cancelling the order, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	now := time.Now().UTC()
+	query := url.Values{
+		"from":   {now.Add(-time.Hour).Format(time.RFC3339)},
+		"to":     {now.Add(time.Hour).Format(time.RFC3339)},
+		"bucket": {"day"},
+	}
+	resp := doRequest(t, http.MethodGet, analyticsURL+"?"+query.Encode(), conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	analytics := models.PackingAnalytics{}
+	decodeData(t, resp, &analytics)
+
+	// 501 items ship in 500 + 250, 251 in 500 and 1000 in 1000, the cancelled order is left out
+	totals := analytics.Totals
+	if totals.Orders != 3 || totals.ItemsOrdered != 1752 || totals.ItemsShipped != 2250 || totals.Overshoot != 498 ||
+		totals.OvershootPercent != 28.42 {
+		t.Errorf("unexpected totals %+v", totals)
+	}
+	if fmt.Sprint(totals.Packs) != "[{1000 1} {500 2} {250 1}]" {
+		t.Errorf("expected packs [{1000 1} {500 2} {250 1}], got %v", totals.Packs)
+	}
+	if len(analytics.Buckets) == 0 || len(analytics.Buckets) > 2 {
+		t.Errorf("expected the range to be in one or two days, got %d buckets", len(analytics.Buckets))
+	}
+
+	testcases := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{name: "defaults", expectedCode: http.StatusOK},
+		{name: "months", query: "?bucket=month&from=2024-01-01T00:00:00Z&to=2024-04-01T00:00:00Z",
+			expectedCode: http.StatusOK},
+		{name: "unknown bucket", query: "?bucket=year", expectedCode: http.StatusBadRequest},
+		{name: "from after to", query: "?from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+			expectedCode: http.StatusBadRequest},
+		{name: "too many buckets", query: "?from=2000-01-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+			expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodGet, analyticsURL+tc.query, conf.AdminToken, nil)
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+
+	resp = doRequest(t, http.MethodGet, analyticsURL+"?bucket=month&from=2024-01-01T00:00:00Z&to=2024-04-01T00:00:00Z",
+		conf.AdminToken, nil)
+	months := models.PackingAnalytics{}
+	decodeData(t, resp, &months)
+	if len(months.Buckets) != 3 || months.Buckets[0].Start != "2024-01-01T00:00:00Z" || months.Totals.Orders != 0 {
+		t.Errorf("expected 3 empty monthly buckets from January, got %+v", months.Buckets)
+	}
+
+	resp = doRequest(t, http.MethodGet, analyticsURL, "", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d without the admin token, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestCatalogRecommendations(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+	recommendationsURL := fmt.Sprintf("http://localhost:%s/analytics/catalog-recommendations", conf.Port)
+
+	createOrder(t, conf, 300)
+	createOrder(t, conf, 300)
+	createOrder(t, conf, 650)
+
+	resp := doRequest(t, http.MethodGet, recommendationsURL+"?sizes=2&min_size=100&max_size=400&step=100&top=3",
+		conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	recommendations := services.CatalogRecommendations{}
+	decodeData(t, resp, &recommendations)
+
+	// the default packs ship 300 in 500 and 650 in 500 + 250, 300 + 400 ship 650 in 700
+	if recommendations.Orders != 3 || recommendations.ItemsOrdered != 1250 || recommendations.SetsSearched != 6 {
+		t.Errorf("expected 6 sets searched for 3 orders of 1250 items, got %+v", recommendations)
+	}
+	if fmt.Sprint(recommendations.Current) != "{[250 500 1000 2000 5000] 500 40 4}" {
+		t.Errorf("unexpected score of the active catalog %+v", recommendations.Current)
+	}
+	if len(recommendations.Recommendations) != 3 ||
+		fmt.Sprint(recommendations.Recommendations[0]) != "{[300 400] 50 4 4}" {
+		t.Errorf("expected 3 sets with 300 and 400 first, got %+v", recommendations.Recommendations)
+	}
+
+	testcases := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{name: "defaults", expectedCode: http.StatusOK},
+		{name: "max size below min size", query: "?min_size=500&max_size=250", expectedCode: http.StatusBadRequest},
+		{name: "too many sets", query: "?min_size=1&max_size=5000&step=1", expectedCode: http.StatusUnprocessableEntity},
+		{name: "no order history", query: "?from=2020-01-01T00:00:00Z&to=2020-02-01T00:00:00Z",
+			expectedCode: http.StatusUnprocessableEntity},
+		{name: "too many recommendations", query: "?top=100", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodGet, recommendationsURL+tc.query, conf.AdminToken, nil)
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+
+	resp = doRequest(t, http.MethodGet, recommendationsURL, "", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d without the admin token, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
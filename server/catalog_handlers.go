@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/services"
+)
+
+func (s *Server) GetPackCatalogsHandler(c *gin.Context) {
+	catalogs, err := s.db.GetPackCatalogs(c.Request.Context())
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting pack catalogs: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", catalogs)
+}
+
+func (s *Server) GetPackCatalogHandler(c *gin.Context) {
+	version, valid := intParam(c, "version")
+	if !valid {
+		return
+	}
+
+	catalog, err := s.db.GetPackCatalog(c.Request.Context(), version)
+	if errors.Is(err, database.ErrNotFound) {
+		notFound(c)
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting pack catalog: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", catalog)
+}
+
+func (s *Server) ActivatePackCatalogHandler(c *gin.Context) {
+	version, valid := intParam(c, "version")
+	if !valid {
+		return
+	}
+
+	catalog, err := s.db.ActivatePackCatalog(c.Request.Context(), version)
+	if errors.Is(err, database.ErrNotFound) {
+		notFound(c)
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error activating pack catalog: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "pack catalog activated successfully", catalog)
+}
+
+// CatalogAnalysisQuery selects the pack sizes to analyse, repeated as in
+// pack_sizes=23&pack_sizes=31, or else the catalog at Version or the active
+// catalog, and the range of quantities to pack with them. The quantities are
+// at most the default order items limit, the service checks the configured one.
+type CatalogAnalysisQuery struct {
+	PackSizes []int `form:"pack_sizes" binding:"omitempty,unique,dive,min=1"`
+	Version   int   `form:"version" binding:"omitempty,min=1"`
+	MinItems  int   `form:"min_items" binding:"omitempty,min=1,max=1000000000000"`
+	MaxItems  int   `form:"max_items" binding:"omitempty,min=1,max=1000000000000"`
+}
+
+// AnalyzePackCatalogHandler responds with which quantities a set of pack
+// sizes ships exactly and how far over it ships the others, so new sizes can
+// be checked before they are activated
+func (s *Server) AnalyzePackCatalogHandler(c *gin.Context) {
+	var analysisQuery CatalogAnalysisQuery
+	if err := c.ShouldBindQuery(&analysisQuery); err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding catalog analysis query: %v", err))
+		badRequest(c, err.Error())
+		return
+	}
+
+	analysis, err := s.orderService.AnalyzeCatalog(c.Request.Context(), services.AnalysisOptions{
+		PackSizes:      analysisQuery.PackSizes,
+		CatalogVersion: analysisQuery.Version,
+		MinItems:       analysisQuery.MinItems,
+		MaxItems:       analysisQuery.MaxItems,
+	})
+	switch {
+	case err == nil:
+		ok(c, "successful", analysis)
+	case errors.Is(err, services.ErrInvalidAnalysisRange):
+		badRequest(c, err.Error())
+	case errors.Is(err, services.ErrUnknownCatalog):
+		unprocessableEntity(c, err.Error())
+	default:
+		s.packingError(c, err)
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/spankie/gymshark/config"
+	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/services"
+)
+
+func TestPackCatalogHandlers(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	dbService := createDBAndHTTPServer(t, &conf)
+
+	url := fmt.Sprintf("http://localhost:%s", conf.Port)
+
+	// adding a pack creates catalog version 2 from the seeded version 1
+	resp := doRequest(t, http.MethodPost, url+"/packs", conf.AdminToken, bytes.NewBufferString(`{ "quantity": 1 }`))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodGet, url+"/catalogs", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	catalogs := []models.PackCatalog{}
+	decodeData(t, resp, &catalogs)
+	if len(catalogs) != 2 || !catalogs[0].Active || catalogs[1].Active {
+		t.Fatalf("expected the new catalog to be the active one, got %+v", catalogs)
+	}
+	if len(catalogs[0].Packs) != 6 || len(catalogs[1].Packs) != 5 {
+		t.Errorf("expected catalogs to have 6 and 5 packs, got %d and %d", len(catalogs[0].Packs), len(catalogs[1].Packs))
+	}
+
+	// orders are pinned to the catalog they were packed against
+	order := createOrder(t, conf, 1)
+	if order.CatalogVersion == nil || *order.CatalogVersion != 2 {
+		t.Errorf("expected order to be packed against catalog 2, got %v", order.CatalogVersion)
+	}
+
+	resp = doRequest(t, http.MethodPost, url+"/catalogs/1/activate", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	order = createOrder(t, conf, 1)
+	if order.CatalogVersion == nil || *order.CatalogVersion != 1 {
+		t.Errorf("expected order to be packed against catalog 1, got %v", order.CatalogVersion)
+	}
+	stored, err := dbService.GetOrder(context.Background(), order.ID)
+	if err != nil {
+		t.Fatalf("expected nil error finding order in db but got: %v", err)
+	}
+	if len(stored.Shipping) != 1 || stored.Shipping[0].PackSize != 250 {
+		t.Errorf("expected order to be shipped in a 250 pack, got %+v", stored.Shipping)
+	}
+
+	resp = doRequest(t, http.MethodGet, url+"/catalogs/1", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	catalog := models.PackCatalog{}
+	decodeData(t, resp, &catalog)
+	if catalog.Version != 1 || !catalog.Active {
+		t.Errorf("expected catalog 1 to be active, got %+v", catalog)
+	}
+
+	resp = doRequest(t, http.MethodGet, url+"/catalogs/100", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodPost, url+"/catalogs/100/activate", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+// createOrder creates an order through the api and returns it
+func createOrder(t *testing.T, conf config.Configuration, numberOfItems int) models.Order {
+	t.Helper()
+	body := bytes.NewBufferString(fmt.Sprintf(`{ "number_of_items": %d }`, numberOfItems))
+	resp := doRequest(t, http.MethodPost, fmt.Sprintf("http://localhost:%s/orders", conf.Port), "", body)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	order := models.Order{}
+	decodeData(t, resp, &order)
+	return order
+}
+
+func TestAnalyzePackCatalog(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+	analysisURL := fmt.Sprintf("http://localhost:%s/catalogs/analysis", conf.Port)
+
+	resp := doRequest(t, http.MethodGet, analysisURL, conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	analysis := services.CatalogAnalysis{}
+	decodeData(t, resp, &analysis)
+	// the default packs ship multiples of 250 exactly and 1 item in a 250 pack
+	if *analysis.CatalogVersion != 1 || analysis.GCD != 250 || analysis.FrobeniusNumber != nil ||
+		analysis.MaxItems != 10000 || len(analysis.Curve) != 10000 || analysis.WorstOvershoot != 249 ||
+		analysis.WorstOvershootItems != 1 || analysis.Unreachable != 10000-40 {
+		t.Errorf("unexpected analysis of the active catalog %+v", analysis)
+	}
+
+	resp = doRequest(t, http.MethodGet, analysisURL+"?pack_sizes=23&pack_sizes=31&pack_sizes=53&max_items=400",
+		conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	analysis = services.CatalogAnalysis{}
+	decodeData(t, resp, &analysis)
+	if analysis.CatalogVersion != nil || analysis.GCD != 1 || *analysis.FrobeniusNumber != 326 ||
+		len(analysis.Curve) != 400 || analysis.Curve[22] != (services.OvershootPoint{NumberOfItems: 23, PackCount: 1}) {
+		t.Errorf("unexpected analysis of 23, 31 and 53 %+v", analysis)
+	}
+
+	testcases := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{name: "catalog", query: "?version=1", expectedCode: http.StatusOK},
+		{name: "unknown catalog", query: "?version=99", expectedCode: http.StatusUnprocessableEntity},
+		{name: "invalid pack size", query: "?pack_sizes=0", expectedCode: http.StatusBadRequest},
+		{name: "max items below min items", query: "?min_items=500&max_items=100",
+			expectedCode: http.StatusBadRequest},
+		{name: "too many quantities", query: "?max_items=100000", expectedCode: http.StatusBadRequest},
+		{name: "too many items", query: "?min_items=9223372036854775000&max_items=9223372036854775807",
+			expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodGet, analysisURL+tc.query, conf.AdminToken, nil)
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+
+	resp = doRequest(t, http.MethodGet, analysisURL, "", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d without the admin token, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/services"
+)
+
+// CustomerRequest creates or replaces a customer. Strategy is the packing
+// strategy of the customer's orders and RefusedPackSizes the pack sizes their
+// orders are never shipped in.
+type CustomerRequest struct {
+	Email            string  `json:"email" binding:"required,email,max=255"`
+	Name             string  `json:"name" binding:"required,max=255"`
+	Strategy         *string `json:"strategy"`
+	RefusedPackSizes []int   `json:"refused_pack_sizes" binding:"omitempty,unique,dive,min=1"`
+}
+
+// strategyErr checks the strategy against the packing strategies of the
+// services, so a new strategy can be set without changing the request
+func (r CustomerRequest) strategyErr() error {
+	if r.Strategy == nil {
+		return nil
+	}
+	_, err := services.GetPackingStrategy(*r.Strategy)
+
+	return err
+}
+
+func (r CustomerRequest) customer() *models.Customer {
+	return &models.Customer{
+		Email:            r.Email,
+		Name:             r.Name,
+		Strategy:         r.Strategy,
+		RefusedPackSizes: r.RefusedPackSizes,
+	}
+}
+
+func (s *Server) CreateCustomerHandler(c *gin.Context) {
+	var customerRequest CustomerRequest
+	err := decode(c, &customerRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding customer request: %v", err))
+		badRequest(c, "")
+		return
+	}
+	if err := customerRequest.strategyErr(); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	customer := customerRequest.customer()
+	err = s.db.CreateCustomer(c.Request.Context(), customer)
+	if errors.Is(err, database.ErrDuplicateEmail) {
+		conflict(c, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error creating customer: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	created(c, "customer created successfully", customer)
+}
+
+func (s *Server) GetCustomersHandler(c *gin.Context) {
+	customers, err := s.db.GetCustomers(c.Request.Context())
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting customers: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", customers)
+}
+
+func (s *Server) GetCustomerHandler(c *gin.Context) {
+	customerID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	customer, err := s.db.GetCustomer(c.Request.Context(), customerID)
+	if errors.Is(err, database.ErrNotFound) {
+		notFound(c)
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting customer: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", customer)
+}
+
+// UpdateCustomerHandler replaces the details and packing settings of a customer,
+// the orders already packed are not changed
+func (s *Server) UpdateCustomerHandler(c *gin.Context) {
+	customerID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	var customerRequest CustomerRequest
+	err := decode(c, &customerRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding customer request: %v", err))
+		badRequest(c, "")
+		return
+	}
+	if err := customerRequest.strategyErr(); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	customer := customerRequest.customer()
+	customer.ID = customerID
+	err = s.db.UpdateCustomer(c.Request.Context(), customer)
+	switch {
+	case err == nil:
+		ok(c, "customer updated successfully", customer)
+	case errors.Is(err, database.ErrNotFound):
+		notFound(c)
+	case errors.Is(err, database.ErrDuplicateEmail):
+		conflict(c, err.Error())
+	default:
+		s.logger.Error(fmt.Sprintf("error updating customer: %v", err))
+		internalServerError(c)
+	}
+}
+
+// DeleteCustomerHandler deletes a customer without orders
+func (s *Server) DeleteCustomerHandler(c *gin.Context) {
+	customerID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	err := s.db.DeleteCustomer(c.Request.Context(), customerID)
+	switch {
+	case err == nil:
+		ok(c, "customer deleted successfully", nil)
+	case errors.Is(err, database.ErrNotFound):
+		notFound(c)
+	case errors.Is(err, database.ErrCustomerHasOrders):
+		conflict(c, err.Error())
+	default:
+		s.logger.Error(fmt.Sprintf("error deleting customer: %v", err))
+		internalServerError(c)
+	}
+}
+
+// GetCustomerOrdersHandler lists the orders of a customer, it takes the
+// filters, sort and pages of the orders list
+func (s *Server) GetCustomerOrdersHandler(c *gin.Context) {
+	customerID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	_, err := s.db.GetCustomer(c.Request.Context(), customerID)
+	if errors.Is(err, database.ErrNotFound) {
+		notFound(c)
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting customer: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	s.listOrders(c, &customerID)
+}
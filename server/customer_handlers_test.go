@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/spankie/gymshark/config"
+	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/services"
+)
+
+// createCustomer creates a customer through the api and returns it
+func createCustomer(t *testing.T, conf config.Configuration, body string) models.Customer {
+	t.Helper()
+	url := fmt.Sprintf("http://localhost:%s/customers", conf.Port)
+	resp := doRequest(t, http.MethodPost, url, conf.AdminToken, bytes.NewBufferString(body))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	customer := models.Customer{}
+	decodeData(t, resp, &customer)
+	return customer
+}
+
+func TestCustomerHandlers(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	url := fmt.Sprintf("http://localhost:%s/customers", conf.Port)
+	customer := createCustomer(t, conf, `{ "email": "ops@acme.test", "name": "Acme" }`)
+
+	testcases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		token        string
+		expectedCode int
+	}{
+		{name: "list without admin token", method: http.MethodGet, expectedCode: http.StatusUnauthorized},
+		{name: "duplicate email", method: http.MethodPost, body: `{ "email": "ops@acme.test", "name": "Acme" }`,
+			token: conf.AdminToken, expectedCode: http.StatusConflict},
+		{name: "invalid email", method: http.MethodPost, body: `{ "email": "acme", "name": "Acme" }`,
+			token: conf.AdminToken, expectedCode: http.StatusBadRequest},
+		{name: "unknown strategy", method: http.MethodPost,
+			body: `{ "email": "a@acme.test", "name": "Acme", "strategy": "biggest" }`, token: conf.AdminToken,
+			expectedCode: http.StatusBadRequest},
+		{name: "update", method: http.MethodPut, path: fmt.Sprintf("/%d", customer.ID),
+			body:  `{ "email": "ops@acme.test", "name": "Acme Ltd", "refused_pack_sizes": [250] }`,
+			token: conf.AdminToken, expectedCode: http.StatusOK},
+		{name: "update unknown customer", method: http.MethodPut, path: "/1000",
+			body: `{ "email": "b@acme.test", "name": "Acme" }`, token: conf.AdminToken, expectedCode: http.StatusNotFound},
+		{name: "get unknown customer", method: http.MethodGet, path: "/1000", token: conf.AdminToken,
+			expectedCode: http.StatusNotFound},
+		{name: "delete", method: http.MethodDelete, path: fmt.Sprintf("/%d", customer.ID), token: conf.AdminToken,
+			expectedCode: http.StatusOK},
+		{name: "delete again", method: http.MethodDelete, path: fmt.Sprintf("/%d", customer.ID),
+			token: conf.AdminToken, expectedCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, tc.method, url+tc.path, tc.token, bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestCustomerOrders(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	customer := createCustomer(t, conf,
+		`{ "email": "ops@acme.test", "name": "Acme", "strategy": "fewest_packs", "refused_pack_sizes": [250] }`)
+	createOrder(t, conf, 1)
+
+	ordersURL := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+	body := fmt.Sprintf(`{ "number_of_items": 501, "customer_id": %d }`, customer.ID)
+	resp := doRequest(t, http.MethodPost, ordersURL, "", bytes.NewBufferString(body))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	order := models.Order{}
+	decodeData(t, resp, &order)
+	// the customer refuses 250 packs, so the fewest packs for 501 items is a 1000 pack
+	if order.Strategy != services.StrategyFewestPacks || len(order.Shipping) != 1 || order.Shipping[0].PackSize != 1000 {
+		t.Errorf("expected the order to be packed in a 1000 pack with the customer's strategy, got %+v", order)
+	}
+
+	body = `{ "number_of_items": 501, "customer_id": 1000 }`
+	resp = doRequest(t, http.MethodPost, ordersURL, "", bytes.NewBufferString(body))
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected status code %d for an unknown customer, got %d", http.StatusUnprocessableEntity,
+			resp.StatusCode)
+	}
+
+	url := fmt.Sprintf("http://localhost:%s/customers/%d", conf.Port, customer.ID)
+	resp = doRequest(t, http.MethodGet, url+"/orders", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	orders := []models.Order{}
+	decodeData(t, resp, &orders)
+	ids := make([]int, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	if !slices.Equal(ids, []int{order.ID}) {
+		t.Errorf("expected the customer's orders to be %v, got %v", []int{order.ID}, ids)
+	}
+
+	resp = doRequest(t, http.MethodDelete, url, conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d for a customer with orders, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// layouts of the shipping of an exported order
+const (
+	// shippingRows exports a row per pack size of an order
+	shippingRows = "rows"
+	// shippingColumns exports a row per order with a column per pack size
+	shippingColumns = "columns"
+)
+
+// ExportOrdersQuery selects the orders exported with the filters of the orders
+// list, the limit and cursor are not used since every order is exported.
+// Shipping is rows, the default, or columns.
+type ExportOrdersQuery struct {
+	OrdersQuery
+	Format     string `form:"format" binding:"required,oneof=csv ndjson xlsx"`
+	Shipping   string `form:"shipping" binding:"omitempty,oneof=rows columns"`
+	CustomerID *int   `form:"customer_id" binding:"omitempty,min=1"`
+}
+
+// orderColumns are the columns of an exported order, before its shipping
+var orderColumns = []string{"id", "created_at", "status", "customer_id", "external_reference",
+	"number_of_items", "strategy", "catalog_version", "cost", "deleted_at"}
+
+// orderExporter flattens orders into the rows of an export
+type orderExporter struct {
+	layout string
+	// sizes are the pack sizes with a column when the layout is columns
+	sizes []int
+}
+
+func (e *orderExporter) header() []string {
+	header := append([]string{}, orderColumns...)
+	if e.layout == shippingColumns {
+		for _, size := range e.sizes {
+			header = append(header, fmt.Sprintf("pack_%d", size))
+		}
+		return header
+	}
+
+	return append(header, "pack_size", "pack_quantity")
+}
+
+// rows returns the rows of an order. In the rows layout an order without
+// shipping still has a row, with empty pack cells.
+func (e *orderExporter) rows(order *models.Order) [][]any {
+	values := []any{order.ID, order.CreatedAt, order.Status, intValue(order.CustomerID),
+		stringValue(order.ExternalReference), order.NumberOfItems, order.Strategy,
+		intValue(order.CatalogVersion), intValue(order.Cost), stringValue(order.DeletedAt)}
+
+	if e.layout == shippingColumns {
+		quantities := make(map[int]int, len(order.Shipping))
+		for _, s := range order.Shipping {
+			quantities[s.PackSize] += s.ShippingPackQuantity
+		}
+		for _, size := range e.sizes {
+			values = append(values, quantities[size])
+		}
+		return [][]any{values}
+	}
+
+	if len(order.Shipping) == 0 {
+		return [][]any{append(values, nil, nil)}
+	}
+	rows := make([][]any, 0, len(order.Shipping))
+	for _, s := range order.Shipping {
+		row := append(append([]any{}, values...), s.PackSize, s.ShippingPackQuantity)
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// intValue is the value of an optional int in an export row
+func intValue(v *int) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// stringValue is the value of an optional string in an export row
+func stringValue(v *string) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// ExportOrdersHandler streams the orders selected by the query as a file.
+// The orders are read from a database cursor and written as they are read,
+// so the export is never held in memory.
+func (s *Server) ExportOrdersHandler(c *gin.Context) {
+	var exportQuery ExportOrdersQuery
+	if err := c.ShouldBindQuery(&exportQuery); err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding export query: %v", err))
+		badRequest(c, err.Error())
+		return
+	}
+	filter := exportQuery.filter(exportQuery.CustomerID)
+	exporter := &orderExporter{layout: exportQuery.Shipping}
+
+	ctx := c.Request.Context()
+	export, err := s.db.ExportOrders(ctx, filter)
+	switch {
+	case errors.Is(err, database.ErrInvalidSort):
+		badRequest(c, err.Error())
+		return
+	case err != nil:
+		s.logger.Error(fmt.Sprintf("error exporting orders: %v", err))
+		internalServerError(c)
+		return
+	}
+	defer func() {
+		if err := export.Close(); err != nil {
+			s.logger.Error(err.Error())
+		}
+	}()
+
+	if exporter.layout == shippingColumns {
+		// read in the snapshot of the export, so no order has a pack size without a column
+		exporter.sizes, err = export.PackSizes(ctx)
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("error getting exported pack sizes: %v", err))
+			internalServerError(c)
+			return
+		}
+	}
+
+	format := exportFormats[exportQuery.Format]
+	c.Header("Content-Type", format.contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="orders.%s"`, format.extension))
+	c.Status(http.StatusOK)
+
+	// the status is sent with the first rows, an error after that can only
+	// cut the file short
+	if err := writeExport(c, export, format, exporter); err != nil {
+		s.logger.Error(fmt.Sprintf("error writing orders export: %v", err))
+		c.Abort()
+	}
+}
+
+// writeExport writes every order of the export in the format
+func writeExport(c *gin.Context, export *database.OrderExport, format exportFormat, exporter *orderExporter) error {
+	writer, err := format.newWriter(c.Writer, exporter.header())
+	if err != nil {
+		return err
+	}
+
+	for {
+		order, err := export.Next(c.Request.Context())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for _, row := range exporter.rows(order) {
+			if err := writer.writeRow(row); err != nil {
+				return fmt.Errorf("could not write order %d: %w", order.ID, err)
+			}
+		}
+	}
+
+	return writer.close()
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+func TestOrderExporterRows(t *testing.T) {
+	reference := "PO-1"
+	order := &models.Order{ID: 1, NumberOfItems: 501, Strategy: "fewest_items", Status: models.OrderPending,
+		ExternalReference: &reference, CreatedAt: "2024-01-01T00:00:00Z",
+		Shipping: []models.OrderShipping{{PackSize: 500, ShippingPackQuantity: 1}, {PackSize: 250, ShippingPackQuantity: 1}}}
+	empty := &models.Order{ID: 2, NumberOfItems: 1, Status: models.OrderPending}
+
+	rows := &orderExporter{layout: shippingRows}
+	if header := rows.header(); fmt.Sprint(header[len(header)-2:]) != "[pack_size pack_quantity]" {
+		t.Errorf("expected the header to end with the pack columns, got %v", header)
+	}
+	got := rows.rows(order)
+	if len(got) != 2 || got[0][4] != "PO-1" || got[0][3] != nil || fmt.Sprint(got[1][10:]) != "[250 1]" {
+		t.Errorf("expected a row per pack size, got %v", got)
+	}
+	if got := rows.rows(empty); len(got) != 1 || got[0][10] != nil {
+		t.Errorf("expected a row with empty pack cells, got %v", got)
+	}
+
+	columns := &orderExporter{layout: shippingColumns, sizes: []int{1000, 500, 250}}
+	if header := columns.header(); fmt.Sprint(header[len(header)-3:]) != "[pack_1000 pack_500 pack_250]" {
+		t.Errorf("expected a column per pack size, got %v", header)
+	}
+	got = columns.rows(order)
+	if len(got) != 1 || fmt.Sprint(got[0][10:]) != "[0 1 1]" {
+		t.Errorf("expected a row with the quantity of each pack size, got %v", got)
+	}
+}
+
+func TestExportWriters(t *testing.T) { //nolint:cyclop
+	header := []string{"id", "reference", "cost"}
+	rows := [][]any{{1, "PO-1", 100}, {2, `a "quoted", <escaped> reference`, nil}, {3, "=1+1", -5}}
+
+	write := func(format string) []byte {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		writer, err := exportFormats[format].newWriter(buf, header)
+		if err != nil {
+			t.Fatalf("failed to create %s writer: %v", format, err)
+		}
+		for _, row := range rows {
+			if err := writer.writeRow(row); err != nil {
+				t.Fatalf("failed to write %s row: %v", format, err)
+			}
+		}
+		if err := writer.close(); err != nil {
+			t.Fatalf("failed to close %s writer: %v", format, err)
+		}
+		return buf.Bytes()
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(write("csv"))).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	if fmt.Sprint(records) != `[[id reference cost] [1 PO-1 100] [2 a "quoted", <escaped> reference ] [3 '=1+1 -5]]` {
+		t.Errorf("unexpected csv records %q", records)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(write("ndjson"))), "\n")
+	expected := []string{`{"id":1,"reference":"PO-1","cost":100}`,
+		`{"id":2,"reference":"a \"quoted\", <escaped> reference","cost":null}`,
+		`{"id":3,"reference":"=1+1","cost":-5}`}
+	if fmt.Sprint(lines) != fmt.Sprint(expected) {
+		t.Errorf("expected ndjson lines %v, got %v", expected, lines)
+	}
+
+	file := write("xlsx")
+	archive, err := zip.NewReader(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		t.Fatalf("failed to read xlsx: %v", err)
+	}
+	sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatalf("failed to open xlsx sheet: %v", err)
+	}
+	content, err := io.ReadAll(sheet)
+	if err != nil {
+		t.Fatalf("failed to read xlsx sheet: %v", err)
+	}
+	for _, cell := range []string{`<c t="inlineStr"><is><t>reference</t></is></c>`, `<c><v>100</v></c>`,
+		`<t>a &#34;quoted&#34;, &lt;escaped&gt; reference</t>`, `<c/></row>`, `<t>&#39;=1+1</t>`, `<c><v>-5</v></c>`} {
+		if !bytes.Contains(content, []byte(cell)) {
+			t.Errorf("expected the xlsx sheet to contain %s, got %s", cell, content)
+		}
+	}
+}
+
+func TestExportOrders(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+	exportURL := fmt.Sprintf("http://localhost:%s/orders/export", conf.Port)
+
+	first := createOrder(t, conf, 501).ID
+	second := createOrder(t, conf, 251).ID
+
+	testcases := []struct {
+		name            string
+		query           string
+		expectedCode    int
+		expectedRecords string
+	}{
+		{name: "rows", query: "?format=csv&sort=created_at", expectedCode: http.StatusOK,
+			expectedRecords: fmt.Sprintf("[[%d pending 501 500 1] [%d pending 501 250 1] [%d pending 251 500 1]]",
+				first, first, second)},
+		{name: "columns", query: "?format=csv&shipping=columns&sort=created_at", expectedCode: http.StatusOK,
+			expectedRecords: fmt.Sprintf("[[%d pending 501 1 1] [%d pending 251 1 0]]", first, second)},
+		{name: "filtered", query: "?format=csv&min_items=500", expectedCode: http.StatusOK,
+			expectedRecords: fmt.Sprintf("[[%d pending 501 500 1] [%d pending 501 250 1]]", first, first)},
+		{name: "no format", expectedCode: http.StatusBadRequest},
+		{name: "unknown layout", query: "?format=csv&shipping=cells", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodGet, exportURL+tc.query, conf.AdminToken, nil)
+			if resp.StatusCode != tc.expectedCode {
+				t.Fatalf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+
+			records, err := csv.NewReader(resp.Body).ReadAll()
+			if err != nil {
+				t.Fatalf("failed to read csv: %v", err)
+			}
+			// the id, status and number of items of each row, and its pack cells
+			var got [][]string
+			for _, record := range records[1:] {
+				got = append(got, append([]string{record[0], record[2], record[5]}, record[len(orderColumns):]...))
+			}
+			if fmt.Sprint(got) != tc.expectedRecords {
+				t.Errorf("expected records %s, got %v", tc.expectedRecords, got)
+			}
+		})
+	}
+
+	resp := doRequest(t, http.MethodGet, exportURL+"?format=csv", "", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d without the admin token, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// exportWriter writes the rows of an export in a file format. The values of
+// a row are ints, strings or nil for empty values.
+type exportWriter interface {
+	writeRow(values []any) error
+	// close writes what is left of the file, it does not close the underlying writer
+	close() error
+}
+
+// exportFormat is a file format orders can be exported in
+type exportFormat struct {
+	contentType string
+	extension   string
+	// newWriter returns a writer of the format that starts with a header row,
+	// ndjson has no header row and uses it as the keys of each line
+	newWriter func(w io.Writer, header []string) (exportWriter, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv":    {contentType: "text/csv; charset=utf-8", extension: "csv", newWriter: newCSVWriter},
+	"ndjson": {contentType: "application/x-ndjson", extension: "ndjson", newWriter: newNDJSONWriter},
+	"xlsx": {contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", extension: "xlsx",
+		newWriter: newXLSXWriter},
+}
+
+// exportString formats a value of an export row as text
+func exportString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// spreadsheetString formats a value of an export row as the text of a
+// spreadsheet cell. Text a spreadsheet would run as a formula starts with a
+// quote, so a reference such as =HYPERLINK(...) is shown as it was entered.
+func spreadsheetString(v any) string {
+	s := exportString(v)
+	if _, ok := v.(string); ok && s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, header []string) (exportWriter, error) {
+	c := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(header))}
+	if err := c.w.Write(header); err != nil {
+		return nil, fmt.Errorf("could not write csv header: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *csvWriter) writeRow(values []any) error {
+	for i, v := range values {
+		c.record[i] = spreadsheetString(v)
+	}
+
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonWriter struct {
+	w *bufio.Writer
+	// keys are the json encoded keys of each value, in the order of the header
+	keys [][]byte
+	line bytes.Buffer
+	// values encodes the values into line, without escaping html
+	values *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer, header []string) (exportWriter, error) {
+	n := &ndjsonWriter{w: bufio.NewWriter(w), keys: make([][]byte, len(header))}
+	n.values = json.NewEncoder(&n.line)
+	n.values.SetEscapeHTML(false)
+	for i, name := range header {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode ndjson key: %w", err)
+		}
+		n.keys[i] = key
+	}
+
+	return n, nil
+}
+
+// writeRow writes a json object per line, with the keys in the order of the header
+func (n *ndjsonWriter) writeRow(values []any) error {
+	n.line.Reset()
+	n.line.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			n.line.WriteByte(',')
+		}
+		n.line.Write(n.keys[i])
+		n.line.WriteByte(':')
+		if err := n.values.Encode(v); err != nil {
+			return fmt.Errorf("could not encode ndjson value: %w", err)
+		}
+		// the encoder ends each value with a newline
+		n.line.Truncate(n.line.Len() - 1)
+	}
+	n.line.WriteString("}\n")
+	_, err := n.w.Write(n.line.Bytes())
+
+	return err
+}
+
+func (n *ndjsonWriter) close() error {
+	return n.w.Flush()
+}
+
+// the parts of an xlsx file with a single sheet, besides the sheet
+var xlsxParts = []struct{ name, content string }{
+	{name: "[Content_Types].xml", content: xml.Header +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{name: "_rels/.rels", content: xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{name: "xl/workbook.xml", content: xml.Header +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Orders" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{name: "xl/_rels/workbook.xml.rels", content: xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxWriter writes a spreadsheet with a single sheet. The sheet is written
+// as the rows come, text is stored in each cell instead of a shared table of
+// strings so nothing has to be kept until the end.
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	row   bytes.Buffer
+}
+
+func newXLSXWriter(w io.Writer, header []string) (exportWriter, error) {
+	z := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("could not create xlsx part %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, fmt.Errorf("could not write xlsx part %s: %w", part.name, err)
+		}
+	}
+
+	sheet, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, fmt.Errorf("could not create xlsx sheet: %w", err)
+	}
+	x := &xlsxWriter{zip: z, sheet: bufio.NewWriter(sheet)}
+	_, err = x.sheet.WriteString(xml.Header +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, fmt.Errorf("could not write xlsx sheet: %w", err)
+	}
+
+	values := make([]any, len(header))
+	for i, name := range header {
+		values[i] = name
+	}
+
+	return x, x.writeRow(values)
+}
+
+func (x *xlsxWriter) writeRow(values []any) error {
+	x.row.Reset()
+	x.row.WriteString("<row>")
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			x.row.WriteString("<c/>")
+		case int:
+			x.row.WriteString("<c><v>" + strconv.Itoa(v) + "</v></c>")
+		default:
+			x.row.WriteString(`<c t="inlineStr"><is><t>`)
+			if err := xml.EscapeText(&x.row, []byte(spreadsheetString(v))); err != nil {
+				return fmt.Errorf("could not write xlsx cell: %w", err)
+			}
+			x.row.WriteString("</t></is></c>")
+		}
+	}
+	x.row.WriteString("</row>")
+	_, err := x.sheet.Write(x.row.Bytes())
+
+	return err
+}
+
+func (x *xlsxWriter) close() error {
+	if _, err := x.sheet.WriteString("</sheetData></worksheet>"); err != nil {
+		return fmt.Errorf("could not write xlsx sheet: %w", err)
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return fmt.Errorf("could not write xlsx sheet: %w", err)
+	}
+
+	return x.zip.Close()
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// idempotencyKeyHeader is the header clients retry a request with to get
+	// the first response instead of repeating the request
+	idempotencyKeyHeader = "Idempotency-Key"
+	// maxIdempotencyKeyLength is the longest idempotency key stored
+	maxIdempotencyKeyLength = 255
+)
+
+// fingerprint identifies a decoded request, so retries with the same fields
+// match whatever their formatting
+func fingerprint(c *gin.Context, request interface{}) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("could not encode request: %w", err)
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.FullPath() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// reserveIdempotencyKey reserves key for the request. It responds and returns
+// false when the key was used before, with the stored response for a retry
+// of the same request and with an error for a different one.
+func (s *Server) reserveIdempotencyKey(c *gin.Context, key string, request interface{}) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		badRequest(c, fmt.Sprintf("%s is longer than %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+		return false
+	}
+
+	requestFingerprint, err := fingerprint(c, request)
+	if err != nil {
+		s.logger.Error(err.Error())
+		internalServerError(c)
+		return false
+	}
+
+	stored, err := s.db.ReserveIdempotencyKey(c.Request.Context(), key, requestFingerprint, s.config.IdempotencyKeyTTL)
+	switch {
+	case err != nil:
+		s.logger.Error(fmt.Sprintf("error reserving idempotency key: %v", err))
+		internalServerError(c)
+		return false
+	case stored == nil:
+		return true
+	case stored.Fingerprint != requestFingerprint:
+		unprocessableEntity(c, fmt.Sprintf("%s was already used for a different request", idempotencyKeyHeader))
+	case stored.Response == nil:
+		conflict(c, fmt.Sprintf("a request with this %s is in progress", idempotencyKeyHeader))
+	default:
+		c.Data(stored.StatusCode, "application/json; charset=utf-8", stored.Response)
+	}
+
+	return false
+}
+
+// releaseIdempotencyKey frees key after its request failed, so it can be retried
+func (s *Server) releaseIdempotencyKey(c *gin.Context, key string) {
+	// the key is released even when the client is gone, so its retry is not blocked
+	err := s.db.ReleaseIdempotencyKey(context.WithoutCancel(c.Request.Context()), key)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error releasing idempotency key: %v", err))
+	}
+}
+
+// createdIdempotent responds like created and stores the response under key,
+// to be replayed to retries of the request
+func (s *Server) createdIdempotent(c *gin.Context, key, message string, data interface{}) {
+	body, err := json.Marshal(response{Data: data, Message: message})
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error encoding response: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	err = s.db.SaveIdempotencyResponse(context.WithoutCancel(c.Request.Context()), key, http.StatusCreated, body)
+	if err != nil {
+		// the order is created, only its retries will fail
+		s.logger.Error(fmt.Sprintf("error saving idempotent response: %v", err))
+	}
+
+	c.Data(http.StatusCreated, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/spankie/gymshark/config"
+)
+
+// createOrderWithKey creates an order with an Idempotency-Key and returns the response
+func createOrderWithKey(t *testing.T, conf config.Configuration, key, body string) *http.Response {
+	t.Helper()
+	url := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set(idempotencyKeyHeader, key)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make request to server: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Errorf("failed to close response body: %v", err)
+		}
+	})
+
+	return resp
+}
+
+func TestIdempotentCreateOrder(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	resp := createOrderWithKey(t, conf, "retry-1", `{ "number_of_items": 501 }`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	first, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+
+	// a retry with the same fields is replayed, however it is formatted
+	resp = createOrderWithKey(t, conf, "retry-1", `{"number_of_items":501}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d for a retry, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	retry, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if !bytes.Equal(first, retry) {
+		t.Errorf("expected the retry to replay %s, got %s", first, retry)
+	}
+
+	resp = createOrderWithKey(t, conf, "retry-1", `{ "number_of_items": 10 }`)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected status code %d for a different body, got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+
+	// a failed request does not keep its key
+	resp = createOrderWithKey(t, conf, "retry-2", `{ "number_of_items": 501, "strategy": "unknown" }`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status code %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+	resp = createOrderWithKey(t, conf, "retry-2", `{ "number_of_items": 10 }`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	if orders := listOrders(t, fmt.Sprintf("http://localhost:%s/orders", conf.Port)); len(orders) != 2 {
+		t.Errorf("expected 2 orders to be created, got %d", len(orders))
+	}
+}
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/services"
+)
+
+const (
+	// maxImportRows is the most orders a single import can create
+	maxImportRows = 10000
+	// maxImportSize is the largest csv file accepted, in bytes
+	maxImportSize = 10 << 20
+	// maxReferenceLength is the longest external reference of an order
+	maxReferenceLength = 255
+)
+
+var errTooManyRows = fmt.Errorf("an import has at most %d orders", maxImportRows)
+
+// ImportOrdersQuery sets how orders are imported. Mode is atomic to create
+// every order or none, the default, or best_effort to create each order that
+// can be. CustomerID places every order of the import for a customer.
+type ImportOrdersQuery struct {
+	Mode       string `form:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	CustomerID *int   `form:"customer_id" binding:"omitempty,min=1"`
+}
+
+// importRow is the result of importing a row of the csv, Row is its line in the file
+type importRow struct {
+	Row       int     `json:"row"`
+	Reference *string `json:"reference,omitempty"`
+	OrderID   *int    `json:"order_id,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// importReport is the result of an import and of each of its rows
+type importReport struct {
+	Created int         `json:"created"`
+	Failed  int         `json:"failed"`
+	Rows    []importRow `json:"rows"`
+}
+
+// validRows returns the index of the rows with a valid order, in the order of the orders
+func (r *importReport) validRows() []int {
+	rows := make([]int, 0, len(r.Rows))
+	for i := range r.Rows {
+		if r.Rows[i].Error == "" {
+			rows = append(rows, i)
+		}
+	}
+
+	return rows
+}
+
+// ImportOrdersHandler creates orders from a csv, sent as the file field of a
+// multipart form or as the request body. The csv has a header row with a
+// quantity column and an optional reference column.
+func (s *Server) ImportOrdersHandler(c *gin.Context) {
+	var importQuery ImportOrdersQuery
+	if err := c.ShouldBindQuery(&importQuery); err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding import query: %v", err))
+		badRequest(c, err.Error())
+		return
+	}
+	atomic := importQuery.Mode != "best_effort"
+
+	file, err := importFile(c)
+	if err != nil {
+		s.importFileError(c, err)
+		return
+	}
+	defer file.Close()
+
+	orders, report, err := parseImport(file, importQuery.CustomerID)
+	if err != nil {
+		s.importFileError(c, err)
+		return
+	}
+
+	rows := report.validRows()
+	if atomic && len(rows) < len(report.Rows) {
+		for _, i := range rows {
+			report.Rows[i].Error = services.ErrOrderNotImported.Error()
+		}
+		s.respondImport(c, report)
+		return
+	}
+
+	imported, err := s.orderService.ImportOrders(c.Request.Context(), orders, atomic)
+	if err != nil {
+		s.packingError(c, err)
+		return
+	}
+
+	for k, result := range imported {
+		row := &report.Rows[rows[k]]
+		if result.Err != nil {
+			row.Error = s.importError(result.Err)
+			continue
+		}
+		row.OrderID = &result.Order.ID
+	}
+	s.respondImport(c, report)
+}
+
+// importFile returns the csv of an import request
+func importFile(c *gin.Context) (io.ReadCloser, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	if c.ContentType() != "multipart/form-data" {
+		return c.Request.Body, nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("could not read the file field: %w", err)
+	}
+
+	return header.Open()
+}
+
+// parseImport reads the orders of a csv, the report has a row for each order
+// and the error of the rows that are not valid
+func parseImport(file io.Reader, customerID *int) ([]*models.Order, *importReport, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read the header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	quantityColumn := slices.Index(header, "quantity")
+	referenceColumn := slices.Index(header, "reference")
+	if quantityColumn < 0 {
+		return nil, nil, errors.New("the header has no quantity column")
+	}
+
+	var orders []*models.Order
+	report := &importReport{Rows: []importRow{}}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read the file: %w", err)
+		}
+		if len(report.Rows) == maxImportRows {
+			return nil, nil, errTooManyRows
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{Row: line}
+		order, err := parseImportRecord(record, quantityColumn, referenceColumn)
+		if err != nil {
+			row.Error = err.Error()
+		} else {
+			order.CustomerID = customerID
+			row.Reference = order.ExternalReference
+			orders = append(orders, order)
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	if len(report.Rows) == 0 {
+		return nil, nil, errors.New("the file has no orders")
+	}
+
+	return orders, report, nil
+}
+
+// parseImportRecord returns the order of a csv record
+func parseImportRecord(record []string, quantityColumn, referenceColumn int) (*models.Order, error) {
+	if quantityColumn >= len(record) {
+		return nil, errors.New("quantity is required")
+	}
+	quantity, err := strconv.Atoi(strings.TrimSpace(record[quantityColumn]))
+	if err != nil || quantity < 1 {
+		return nil, fmt.Errorf("quantity must be a positive number, got %q", record[quantityColumn])
+	}
+
+	order := &models.Order{NumberOfItems: quantity}
+	if referenceColumn >= 0 && referenceColumn < len(record) {
+		reference := strings.TrimSpace(record[referenceColumn])
+		if len(reference) > maxReferenceLength {
+			return nil, fmt.Errorf("reference is longer than %d characters", maxReferenceLength)
+		}
+		if reference != "" {
+			order.ExternalReference = &reference
+		}
+	}
+
+	return order, nil
+}
+
+// importFileError responds to a csv that cannot be imported
+func (s *Server) importFileError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		requestEntityTooLarge(c, fmt.Sprintf("the file is larger than %d bytes", maxImportSize))
+	case errors.Is(err, errTooManyRows):
+		requestEntityTooLarge(c, err.Error())
+	default:
+		s.logger.Debug(fmt.Sprintf("Error reading import: %v", err))
+		badRequest(c, err.Error())
+	}
+}
+
+// importError describes why an order of an import was not created
+func (s *Server) importError(err error) string {
+	for _, known := range []error{services.ErrUnknownStrategy, services.ErrUnknownCustomer, services.ErrTooManyItems,
+		services.ErrNoPacking, services.ErrPackingLimit, services.ErrOrderNotImported, services.ErrImportStopped,
+		database.ErrInsufficientStock} {
+		if errors.Is(err, known) {
+			return err.Error()
+		}
+	}
+
+	s.logger.Error(fmt.Sprintf("error importing order: %v", err))
+	return "the order could not be created"
+}
+
+// respondImport responds with the report of an import, it is created when
+// every order was created and unprocessable when none was
+func (s *Server) respondImport(c *gin.Context, report *importReport) {
+	for _, row := range report.Rows {
+		if row.Error == "" {
+			report.Created++
+		} else {
+			report.Failed++
+		}
+	}
+
+	switch {
+	case report.Failed == 0:
+		created(c, "orders imported successfully", report)
+	case report.Created == 0:
+		respondJSON(c, http.StatusUnprocessableEntity, "", "no orders were imported", report)
+	default:
+		ok(c, "some orders were imported", report)
+	}
+}
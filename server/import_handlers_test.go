@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spankie/gymshark/config"
+	"github.com/spankie/gymshark/database/models"
+)
+
+func TestParseImport(t *testing.T) {
+	testcases := []struct {
+		name           string
+		csv            string
+		expectedErr    bool
+		expectedOrders []int
+		expectedErrors []string
+	}{
+		{name: "quantities and references", csv: "reference,quantity\nPO-1,10\n PO-2 , 501\n",
+			expectedOrders: []int{10, 501}, expectedErrors: []string{"", ""}},
+		{name: "quantities only", csv: "Quantity\n10\n", expectedOrders: []int{10}, expectedErrors: []string{""}},
+		{name: "invalid rows", csv: "quantity,reference\n0,PO-1\nten,PO-2\n\n5\n",
+			expectedOrders: []int{5}, expectedErrors: []string{"positive", "positive", ""}},
+		{name: "missing quantity", csv: "reference,quantity\nPO-1\n",
+			expectedErrors: []string{"quantity is required"}},
+		{name: "reference too long", csv: "quantity,reference\n1," + strings.Repeat("a", 256) + "\n",
+			expectedErrors: []string{"longer"}},
+		{name: "no quantity column", csv: "reference\nPO-1\n", expectedErr: true},
+		{name: "empty file", csv: "", expectedErr: true},
+		{name: "no orders", csv: "quantity\n", expectedErr: true},
+		{name: "too many orders", csv: "quantity\n" + strings.Repeat("1\n", maxImportRows+1), expectedErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			orders, report, err := parseImport(strings.NewReader(tc.csv), nil)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected an error %v but got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				return
+			}
+
+			var items []int
+			for _, order := range orders {
+				items = append(items, order.NumberOfItems)
+			}
+			if fmt.Sprint(items) != fmt.Sprint(tc.expectedOrders) {
+				t.Errorf("expected orders of %v items but got %v", tc.expectedOrders, items)
+			}
+			if len(report.Rows) != len(tc.expectedErrors) {
+				t.Fatalf("expected %d rows but got %+v", len(tc.expectedErrors), report.Rows)
+			}
+			for i, expected := range tc.expectedErrors {
+				row := report.Rows[i]
+				if (expected == "") != (row.Error == "") || !strings.Contains(row.Error, expected) {
+					t.Errorf("expected row %d error to contain %q but got %q", row.Row, expected, row.Error)
+				}
+			}
+		})
+	}
+}
+
+// importOrders imports a csv sent as the request body and returns the response
+func importOrders(t *testing.T, conf config.Configuration, query, csv string) *http.Response {
+	t.Helper()
+	url := fmt.Sprintf("http://localhost:%s/orders/import%s", conf.Port, query)
+	return doRequest(t, http.MethodPost, url, conf.AdminToken, bytes.NewBufferString(csv))
+}
+
+func TestImportOrders(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	conf.MaxOrderItems = 10000
+	createDBAndHTTPServer(t, &conf)
+	ordersURL := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+
+	testcases := []struct {
+		name           string
+		query          string
+		csv            string
+		expectedCode   int
+		expectedOrders int
+	}{
+		{name: "atomic with an invalid row", csv: "quantity,reference\n10,PO-1\nten,PO-2\n",
+			expectedCode: http.StatusUnprocessableEntity},
+		{name: "atomic with too many items", query: "?mode=atomic", csv: "quantity\n10\n20000\n",
+			expectedCode: http.StatusUnprocessableEntity},
+		{name: "atomic", query: "?mode=atomic", csv: "quantity,reference\n10,PO-1\n501,PO-2\n",
+			expectedCode: http.StatusCreated, expectedOrders: 2},
+		{name: "best effort with failed rows", query: "?mode=best_effort", csv: "quantity\n251\n0\n20000\n",
+			expectedCode: http.StatusOK, expectedOrders: 3},
+		{name: "unknown customer", query: "?customer_id=1000", csv: "quantity\n1\n",
+			expectedCode: http.StatusUnprocessableEntity, expectedOrders: 3},
+		{name: "unknown mode", query: "?mode=some", csv: "quantity\n1\n", expectedCode: http.StatusBadRequest,
+			expectedOrders: 3},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := importOrders(t, conf, tc.query, tc.csv)
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+			if orders := listOrders(t, ordersURL); len(orders) != tc.expectedOrders {
+				t.Errorf("expected %d orders, got %d", tc.expectedOrders, len(orders))
+			}
+		})
+	}
+
+	orders := listOrders(t, ordersURL+"?sort=created_at")
+	if orders[0].ExternalReference == nil || *orders[0].ExternalReference != "PO-1" {
+		t.Errorf("expected the first order to have reference PO-1, got %+v", orders[0])
+	}
+
+	resp := doRequest(t, http.MethodPost, ordersURL+"/import", "", bytes.NewBufferString("quantity\n1\n"))
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d without the admin token, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestImportOrdersMultipart(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	file, err := form.CreateFormFile("file", "orders.csv")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	if _, err := file.Write([]byte("quantity,reference\n1,PO-1\n501,PO-2\n")); err != nil {
+		t.Fatalf("failed to write form file: %v", err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("failed to close form: %v", err)
+	}
+
+	url := fmt.Sprintf("http://localhost:%s/orders/import", conf.Port)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, body)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+conf.AdminToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make request to server: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Errorf("failed to close response body: %v", err)
+		}
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	report := importReport{}
+	decodeData(t, resp, &report)
+	if report.Created != 2 || report.Failed != 0 || report.Rows[1].OrderID == nil {
+		t.Errorf("expected 2 orders to be created, got %+v", report)
+	}
+	order := models.Order{}
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, *report.Rows[1].OrderID),
+		"", nil)
+	decodeData(t, resp, &order)
+	if order.NumberOfItems != 501 || order.ExternalReference == nil || *order.ExternalReference != "PO-2" {
+		t.Errorf("expected order PO-2 of 501 items, got %+v", order)
+	}
+}
@@ -0,0 +1,22 @@
+package server
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdmin only lets requests through when they carry the configured admin
+// token as a bearer token. All admin requests are rejected when no token is configured.
+func (s *Server) requireAdmin(c *gin.Context) {
+	token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if s.config.AdminToken == "" || !found ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AdminToken)) != 1 {
+		unauthorized(c)
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
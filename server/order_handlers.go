@@ -1,15 +1,39 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
 	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/services"
 )
 
+// CreateOrderRequest is either a number of items packed with the global
+// catalog, or lines of products that are each packed with their own catalog.
+// Explain is the number of ranked packings returned to explain the packing.
+// CustomerID places the order for a customer, it is packed with their settings.
 type CreateOrderRequest struct {
-	NumberOfItems int `json:"number_of_items" binding:"required,min=1"`
+	NumberOfItems int                `json:"number_of_items" binding:"omitempty,min=1"`
+	Lines         []OrderLineRequest `json:"lines" binding:"omitempty,unique=ProductID,dive"`
+	Strategy      string             `json:"strategy"`
+	Explain       int                `json:"explain" binding:"omitempty,min=1,max=10"`
+	CustomerID    *int               `json:"customer_id" binding:"omitempty,min=1"`
+}
+
+// explainedOrder is an order and the explanation of how it was packed
+type explainedOrder struct {
+	*models.Order
+	Explanations []services.Explanation `json:"explanations,omitempty"`
+}
+
+type OrderLineRequest struct {
+	ProductID int `json:"product_id" binding:"required,min=1"`
+	Quantity  int `json:"quantity" binding:"required,min=1"`
 }
 
 func (s *Server) CreateOrderHandler(c *gin.Context) {
@@ -21,16 +45,44 @@ func (s *Server) CreateOrderHandler(c *gin.Context) {
 		return
 	}
 
+	if (orderRequest.NumberOfItems == 0) == (len(orderRequest.Lines) == 0) {
+		badRequest(c, "either number_of_items or lines is required")
+		return
+	}
+
+	idempotencyKey := c.GetHeader(idempotencyKeyHeader)
+	if idempotencyKey != "" && !s.reserveIdempotencyKey(c, idempotencyKey, orderRequest) {
+		return
+	}
+
 	order := &models.Order{
 		NumberOfItems: orderRequest.NumberOfItems,
+		Strategy:      orderRequest.Strategy,
+		CustomerID:    orderRequest.CustomerID,
+	}
+	for _, line := range orderRequest.Lines {
+		order.Lines = append(order.Lines, models.OrderLine{
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+		})
 	}
-	err = s.orderService.CreateOrder(c.Request.Context(), order)
+
+	explanations, err := s.orderService.CreateOrder(c.Request.Context(), order,
+		services.OrderOptions{Explain: orderRequest.Explain})
 	if err != nil {
-		internalServerError(c)
+		if idempotencyKey != "" {
+			s.releaseIdempotencyKey(c, idempotencyKey)
+		}
+		s.packingError(c, err)
 		return
 	}
 
-	created(c, "order created successfully", order)
+	createdOrder := explainedOrder{Order: order, Explanations: explanations}
+	if idempotencyKey != "" {
+		s.createdIdempotent(c, idempotencyKey, "order created successfully", createdOrder)
+		return
+	}
+	created(c, "order created successfully", createdOrder)
 }
 
 func (s *Server) GetOrderHandler(c *gin.Context) {
@@ -51,13 +103,224 @@ func (s *Server) GetOrderHandler(c *gin.Context) {
 	ok(c, "successful", order)
 }
 
+// OrdersQuery filters, sorts and pages the orders listed. Times are RFC 3339,
+// and Cursor is the next_cursor of the previous page.
+type OrdersQuery struct {
+	IncludeDeleted bool       `form:"include_deleted"`
+	CreatedFrom    *time.Time `form:"created_from"`
+	CreatedTo      *time.Time `form:"created_to"`
+	MinItems       *int       `form:"min_items" binding:"omitempty,min=1"`
+	MaxItems       *int       `form:"max_items" binding:"omitempty,min=1"`
+	PackSize       *int       `form:"pack_size" binding:"omitempty,min=1"`
+	Status         string     `form:"status" binding:"omitempty,oneof=pending packed shipped delivered cancelled"`
+	Sort           string     `form:"sort" binding:"omitempty,oneof=created_at -created_at number_of_items -number_of_items"`
+	Limit          int        `form:"limit" binding:"omitempty,min=1,max=200"`
+	Cursor         string     `form:"cursor"`
+}
+
+// filter returns the filter of the query, only the orders of the customer
+// when customerID is set
+func (q *OrdersQuery) filter(customerID *int) database.OrdersFilter {
+	return database.OrdersFilter{
+		IncludeDeleted: q.IncludeDeleted,
+		CreatedFrom:    q.CreatedFrom,
+		CreatedTo:      q.CreatedTo,
+		MinItems:       q.MinItems,
+		MaxItems:       q.MaxItems,
+		PackSize:       q.PackSize,
+		Status:         q.Status,
+		CustomerID:     customerID,
+		Sort:           q.Sort,
+		Limit:          q.Limit,
+		Cursor:         q.Cursor,
+	}
+}
+
 func (s *Server) GetAllOrdersHandler(c *gin.Context) {
-	shipping, err := s.db.GetOrdersShipping(c.Request.Context())
+	s.listOrders(c, nil)
+}
+
+// listOrders responds with the orders selected by the query, only the orders
+// of the customer when customerID is set
+func (s *Server) listOrders(c *gin.Context, customerID *int) {
+	var ordersQuery OrdersQuery
+	if err := c.ShouldBindQuery(&ordersQuery); err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding orders query: %v", err))
+		badRequest(c, err.Error())
+		return
+	}
+
+	page, err := s.db.GetOrdersShipping(c.Request.Context(), ordersQuery.filter(customerID))
+	switch {
+	case err == nil:
+		okPage(c, "successful", page.Orders, page.NextCursor)
+	case errors.Is(err, database.ErrInvalidCursor), errors.Is(err, database.ErrInvalidSort):
+		badRequest(c, err.Error())
+	default:
+		s.logger.Error(fmt.Sprintf("error getting orders: %v", err))
+		internalServerError(c)
+	}
+}
+
+type DeleteOrderRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// DeleteOrderHandler soft deletes an order, it is hidden from the orders
+// list until it is restored
+func (s *Server) DeleteOrderHandler(c *gin.Context) {
+	orderID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	var deleteRequest DeleteOrderRequest
+	err := decode(c, &deleteRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding delete order request: %v", err))
+		badRequest(c, "a reason is required to delete an order")
+		return
+	}
+
+	err = s.db.DeleteOrder(c.Request.Context(), orderID, deleteRequest.Reason)
+	if err != nil {
+		s.orderDeleteError(c, err)
+		return
+	}
+
+	ok(c, "order deleted successfully", nil)
+}
+
+// RestoreOrderHandler brings back a deleted order
+func (s *Server) RestoreOrderHandler(c *gin.Context) {
+	orderID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	err := s.db.RestoreOrder(c.Request.Context(), orderID)
+	if err != nil {
+		s.orderDeleteError(c, err)
+		return
+	}
+
+	order, err := s.db.GetOrder(c.Request.Context(), orderID)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting order: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "order restored successfully", order)
+}
+
+// orderDeleteError maps errors from deleting or restoring an order to a response
+func (s *Server) orderDeleteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		notFound(c)
+	case errors.Is(err, database.ErrOrderDeleted), errors.Is(err, database.ErrOrderNotDeleted),
+		errors.Is(err, database.ErrInsufficientStock):
+		conflict(c, err.Error())
+	default:
+		s.logger.Error(fmt.Sprintf("error deleting or restoring order: %v", err))
+		internalServerError(c)
+	}
+}
+
+type TransitionOrderRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// TransitionOrderHandler moves an order to the next status of its lifecycle
+func (s *Server) TransitionOrderHandler(c *gin.Context) {
+	orderID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	var transitionRequest TransitionOrderRequest
+	err := decode(c, &transitionRequest)
 	if err != nil {
-		errMessage := fmt.Sprintf("error getting order: %v", err)
-		s.logger.Error(errMessage)
+		s.logger.Debug(fmt.Sprintf("Error decoding order transition request: %v", err))
+		badRequest(c, "status is required")
+		return
+	}
+
+	order, err := s.orderService.TransitionOrder(c.Request.Context(), orderID, transitionRequest.Status)
+	switch {
+	case err == nil:
+		ok(c, "order status updated successfully", order)
+	case errors.Is(err, database.ErrNotFound):
+		notFound(c)
+	case errors.Is(err, services.ErrUnknownStatus):
+		badRequest(c, err.Error())
+	case errors.Is(err, services.ErrIllegalTransition), errors.Is(err, database.ErrStatusChanged),
+		errors.Is(err, database.ErrOrderDeleted):
+		conflict(c, err.Error())
+	default:
+		s.logger.Error(fmt.Sprintf("error updating order status: %v", err))
 		internalServerError(c)
 	}
+}
+
+// AmendOrderRequest changes the number of items of the order at Version
+type AmendOrderRequest struct {
+	NumberOfItems int `json:"number_of_items" binding:"required,min=1"`
+	Version       int `json:"version" binding:"required,min=1"`
+}
+
+// AmendOrderHandler packs a pending order again for a new number of items
+func (s *Server) AmendOrderHandler(c *gin.Context) {
+	orderID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	var amendRequest AmendOrderRequest
+	err := decode(c, &amendRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding order amend request: %v", err))
+		badRequest(c, "number of items and version are required")
+		return
+	}
+
+	order, err := s.orderService.AmendOrder(c.Request.Context(), orderID, amendRequest.NumberOfItems,
+		amendRequest.Version)
+	switch {
+	case err == nil:
+		ok(c, "order updated successfully", order)
+	case errors.Is(err, database.ErrNotFound):
+		notFound(c)
+	case errors.Is(err, database.ErrVersionConflict), errors.Is(err, database.ErrOrderDeleted),
+		errors.Is(err, services.ErrOrderNotPending):
+		conflict(c, err.Error())
+	case errors.Is(err, services.ErrNotAmendable):
+		unprocessableEntity(c, err.Error())
+	default:
+		s.packingError(c, err)
+	}
+}
 
-	ok(c, "successful", shipping)
+// packingError maps errors from packing an order to a response
+func (s *Server) packingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownStrategy):
+		badRequest(c, err.Error())
+	case errors.Is(err, services.ErrTooManyItems):
+		requestEntityTooLarge(c, err.Error())
+	case errors.Is(err, services.ErrUnknownProduct), errors.Is(err, services.ErrUnknownCustomer),
+		errors.Is(err, services.ErrNoPacking),
+		errors.Is(err, services.ErrPackingLimit):
+		unprocessableEntity(c, err.Error())
+	case errors.Is(err, context.Canceled):
+		s.logger.Debug(fmt.Sprintf("packing cancelled: %v", err))
+		clientClosedRequest(c)
+	case errors.Is(err, database.ErrInsufficientStock):
+		// the stock changed while the order was packed, packing it again may succeed
+		conflict(c, err.Error())
+	default:
+		s.logger.Error(fmt.Sprintf("error packing order: %v", err))
+		internalServerError(c)
+	}
 }
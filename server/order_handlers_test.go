@@ -3,13 +3,19 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"math"
 	"net/http"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/spankie/gymshark/config"
 	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/services"
 )
 
 func getDefaultConfig() config.Configuration {
@@ -20,6 +26,9 @@ func getDefaultConfig() config.Configuration {
 		DbUsername: "spankie",
 		DbPassword: "spankie",
 		DbName:     "gymshark",
+		AdminToken: "admin-token",
+
+		IdempotencyKeyTTL: time.Hour,
 	}
 }
 
@@ -286,3 +295,601 @@ func TestGetAllOrderHandler(t *testing.T) {
 		t.Fatalf("expected len of shipping to be %d but got %v", 2, l)
 	}
 }
+
+func TestCreateOrderWithStrategy(t *testing.T) {
+	conf := getDefaultConfig()
+	dbService := createDBAndHTTPServer(t, &conf)
+
+	testcases := []struct {
+		name             string
+		body             string
+		expectedCode     int
+		expectedStrategy string
+		expectedPacks    int
+	}{
+		{
+			name:             "default strategy",
+			body:             `{ "number_of_items": 501 }`,
+			expectedCode:     http.StatusCreated,
+			expectedStrategy: services.StrategyLeastOvershoot,
+			expectedPacks:    2,
+		},
+		{
+			name:             "fewest packs strategy",
+			body:             `{ "number_of_items": 501, "strategy": "fewest_packs" }`,
+			expectedCode:     http.StatusCreated,
+			expectedStrategy: services.StrategyFewestPacks,
+			expectedPacks:    1,
+		},
+		{
+			name:         "exact strategy without an exact packing",
+			body:         `{ "number_of_items": 501, "strategy": "exact" }`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "unknown strategy",
+			body:         `{ "number_of_items": 501, "strategy": "cheapest" }`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			url := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+			resp := doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Fatalf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+			if tc.expectedCode != http.StatusCreated {
+				return
+			}
+
+			order := models.Order{}
+			decodeData(t, resp, &order)
+			stored, err := dbService.GetOrder(context.Background(), order.ID)
+			if err != nil {
+				t.Fatalf("expected nil error finding order in db but got: %v", err)
+			}
+			if stored.Strategy != tc.expectedStrategy {
+				t.Errorf("expected order strategy to be %s but got %s", tc.expectedStrategy, stored.Strategy)
+			}
+			if len(stored.Shipping) != tc.expectedPacks {
+				t.Errorf("expected %d pack sizes but got %+v", tc.expectedPacks, stored.Shipping)
+			}
+		})
+	}
+}
+
+// setPackCost sets the unit cost of the active pack with the given size
+func setPackCost(t *testing.T, conf config.Configuration, size, unitCost int) {
+	t.Helper()
+	url := fmt.Sprintf("http://localhost:%s/packs", conf.Port)
+	resp := doRequest(t, http.MethodGet, url, conf.AdminToken, nil)
+	packs := []models.ShippingPack{}
+	decodeData(t, resp, &packs)
+
+	i := slices.IndexFunc(packs, func(p models.ShippingPack) bool { return p.Quantity == size })
+	if i < 0 {
+		t.Fatalf("expected a %d pack in %+v", size, packs)
+	}
+
+	body := bytes.NewBufferString(fmt.Sprintf(`{ "quantity": %d, "unit_cost": %d }`, size, unitCost))
+	resp = doRequest(t, http.MethodPut, fmt.Sprintf("%s/%d", url, packs[i].ID), conf.AdminToken, body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestCreateOrderCost(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	setPackCost(t, conf, 250, 50)
+	setPackCost(t, conf, 500, 10)
+	resp := doRequest(t, http.MethodPut, fmt.Sprintf("http://localhost:%s/packs/shipment-fee", conf.Port),
+		conf.AdminToken, bytes.NewBufferString(`{ "shipment_fee": 100 }`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	testcases := []struct {
+		name          string
+		body          string
+		expectedCost  int
+		expectedPacks map[int]int
+	}{
+		{
+			name:          "least overshoot",
+			body:          `{ "number_of_items": 1 }`,
+			expectedCost:  150,
+			expectedPacks: map[int]int{250: 1},
+		},
+		{
+			name:          "least cost",
+			body:          `{ "number_of_items": 1, "strategy": "least_cost" }`,
+			expectedCost:  110,
+			expectedPacks: map[int]int{500: 1},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			url := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+			resp := doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(tc.body))
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+			}
+
+			order := models.Order{}
+			decodeData(t, resp, &order)
+			if order.Cost == nil || *order.Cost != tc.expectedCost {
+				t.Errorf("expected order cost to be %d but got %v", tc.expectedCost, order.Cost)
+			}
+
+			packs := map[int]int{}
+			for _, shipping := range order.Shipping {
+				packs[shipping.PackSize] = shipping.ShippingPackQuantity
+			}
+			if !maps.Equal(packs, tc.expectedPacks) {
+				t.Errorf("expected packs to be %v but got %v", tc.expectedPacks, packs)
+			}
+		})
+	}
+}
+
+func TestPackingLimits(t *testing.T) {
+	conf := getDefaultConfig()
+	conf.MaxOrderItems = 10000
+	conf.MaxPackingTable = 50_000
+	createDBAndHTTPServer(t, &conf)
+
+	testcases := []struct {
+		name         string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "order within limits",
+			path:         "/orders",
+			body:         `{ "number_of_items": 10000 }`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "order with too many items",
+			path:         "/orders",
+			body:         `{ "number_of_items": 10001 }`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "quote with too many items",
+			path:         "/quotes",
+			body:         `{ "number_of_items": 10001 }`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "quote over the packing table limit",
+			path:         "/quotes",
+			body:         `{ "number_of_items": 10000, "pack_sizes": [99991, 99989] }`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			url := fmt.Sprintf("http://localhost:%s%s", conf.Port, tc.path)
+			resp := doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestCreateOrderOverInt32Items(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	// one item more than an INT column holds
+	const numberOfItems = math.MaxInt32 + 2
+	order := createOrder(t, conf, numberOfItems)
+	createOrder(t, conf, 1)
+
+	url := fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, order.ID)
+	resp := doRequest(t, http.MethodGet, url, "", nil)
+	decodeData(t, resp, &order)
+	total := 0
+	for _, shipping := range order.Shipping {
+		total += shipping.PackSize * shipping.ShippingPackQuantity
+	}
+	if order.NumberOfItems != numberOfItems || total < numberOfItems || total >= numberOfItems+250 {
+		t.Errorf("expected an order of %d items shipped with less than a pack over, got %+v", numberOfItems, order)
+	}
+
+	ordersURL := fmt.Sprintf("http://localhost:%s/orders?sort=-number_of_items&limit=1", conf.Port)
+	items, cursor := listOrdersPage(t, ordersURL)
+	if !slices.Equal(items, []int{numberOfItems}) || cursor == "" {
+		t.Fatalf("expected the large order first with a next page, got %v and %q", items, cursor)
+	}
+	if items, _ := listOrdersPage(t, ordersURL+"&cursor="+cursor); !slices.Equal(items, []int{1}) {
+		t.Errorf("expected the small order after the large one, got %v", items)
+	}
+}
+
+// transitionOrder moves an order to status and returns the response
+func transitionOrder(t *testing.T, conf config.Configuration, orderID int, status string) *http.Response {
+	t.Helper()
+	url := fmt.Sprintf("http://localhost:%s/orders/%d/transitions", conf.Port, orderID)
+	body := bytes.NewBufferString(fmt.Sprintf(`{ "status": %q }`, status))
+	return doRequest(t, http.MethodPost, url, conf.AdminToken, body)
+}
+
+func TestOrderTransitions(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	order := createOrder(t, conf, 1)
+	if order.Status != models.OrderPending {
+		t.Fatalf("expected a new order to be %s, got %s", models.OrderPending, order.Status)
+	}
+
+	testcases := []struct {
+		name         string
+		orderID      int
+		status       string
+		expectedCode int
+	}{
+		{name: "pack", orderID: order.ID, status: models.OrderPacked, expectedCode: http.StatusOK},
+		{name: "skip delivery", orderID: order.ID, status: models.OrderDelivered, expectedCode: http.StatusConflict},
+		{name: "unknown status", orderID: order.ID, status: "lost", expectedCode: http.StatusBadRequest},
+		{name: "ship", orderID: order.ID, status: models.OrderShipped, expectedCode: http.StatusOK},
+		{name: "cancel shipped order", orderID: order.ID, status: models.OrderCancelled, expectedCode: http.StatusConflict},
+		{name: "deliver", orderID: order.ID, status: models.OrderDelivered, expectedCode: http.StatusOK},
+		{name: "unknown order", orderID: order.ID + 100, status: models.OrderPacked, expectedCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := transitionOrder(t, conf, tc.orderID, tc.status)
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+
+	resp := doRequest(t, http.MethodGet, fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, order.ID), "", nil)
+	decodeData(t, resp, &order)
+	var history []string
+	for _, transition := range order.Transitions {
+		history = append(history, transition.To)
+	}
+	expected := []string{models.OrderPending, models.OrderPacked, models.OrderShipped, models.OrderDelivered}
+	if order.Status != models.OrderDelivered || !slices.Equal(history, expected) {
+		t.Errorf("expected a delivered order with history %v, got %s with %v", expected, order.Status, history)
+	}
+
+	resp = transitionOrder(t, conf, order.ID, models.OrderPacked)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestCancelOrderReturnsStock(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	setPackStock(t, conf, 250, 1)
+	order := createOrder(t, conf, 1)
+
+	resp := transitionOrder(t, conf, order.ID, models.OrderCancelled)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("http://localhost:%s/stock", conf.Port), conf.AdminToken, nil)
+	stock := []models.PackStock{}
+	decodeData(t, resp, &stock)
+	if len(stock) != 1 || stock[0].Quantity != 1 {
+		t.Errorf("expected the 250 pack to be back in stock, got %+v", stock)
+	}
+}
+
+// listOrders returns the orders listed at url
+func listOrders(t *testing.T, url string) []models.Order {
+	t.Helper()
+	resp := doRequest(t, http.MethodGet, url, "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	orders := []models.Order{}
+	decodeData(t, resp, &orders)
+	return orders
+}
+
+func TestDeleteAndRestoreOrder(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	order := createOrder(t, conf, 1)
+	createOrder(t, conf, 251)
+	url := fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, order.ID)
+
+	testcases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{name: "delete without reason", method: http.MethodDelete, body: `{}`, expectedCode: http.StatusBadRequest},
+		{name: "delete", method: http.MethodDelete, body: `{ "reason": "duplicate order" }`, expectedCode: http.StatusOK},
+		{name: "delete again", method: http.MethodDelete, body: `{ "reason": "duplicate order" }`, expectedCode: http.StatusConflict},
+		{name: "move deleted order", method: http.MethodPost, path: "/transitions", body: `{ "status": "packed" }`,
+			expectedCode: http.StatusConflict},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, tc.method, url+tc.path, conf.AdminToken, bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+
+	ordersURL := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+	if orders := listOrders(t, ordersURL); len(orders) != 1 || orders[0].ID == order.ID {
+		t.Errorf("expected the deleted order to be hidden, got %+v", orders)
+	}
+	orders := listOrders(t, ordersURL+"?include_deleted=true")
+	i := slices.IndexFunc(orders, func(o models.Order) bool { return o.ID == order.ID })
+	if len(orders) != 2 || i < 0 || orders[i].DeletedReason == nil || *orders[i].DeletedReason != "duplicate order" {
+		t.Errorf("expected the deleted order to be listed with its reason, got %+v", orders)
+	}
+
+	resp := doRequest(t, http.MethodPost, url+"/restore", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	decodeData(t, resp, &order)
+	if order.DeletedAt != nil {
+		t.Errorf("expected the restored order not to be deleted, got %+v", order)
+	}
+	if orders := listOrders(t, ordersURL); len(orders) != 2 {
+		t.Errorf("expected the restored order to be listed, got %+v", orders)
+	}
+
+	resp = doRequest(t, http.MethodPost, url+"/restore", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestDeleteOrderReturnsStock(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	setPackStock(t, conf, 250, 1)
+	order := createOrder(t, conf, 1)
+	url := fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, order.ID)
+
+	body := bytes.NewBufferString(`{ "reason": "duplicate order" }`)
+	resp := doRequest(t, http.MethodDelete, url, conf.AdminToken, body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// another order takes the pack back in stock, so the deleted order can't be restored
+	other := createOrder(t, conf, 1)
+	resp = doRequest(t, http.MethodPost, url+"/restore", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status code %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	resp = transitionOrder(t, conf, other.ID, models.OrderCancelled)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	resp = doRequest(t, http.MethodPost, url+"/restore", conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("http://localhost:%s/stock", conf.Port), conf.AdminToken, nil)
+	stock := []models.PackStock{}
+	decodeData(t, resp, &stock)
+	if len(stock) != 1 || stock[0].Quantity != 0 {
+		t.Errorf("expected the restored order to take the 250 pack from stock again, got %+v", stock)
+	}
+}
+
+func TestCancelDeletedOrder(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	setPackStock(t, conf, 250, 1)
+	order := createOrder(t, conf, 1)
+	url := fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, order.ID)
+
+	body := bytes.NewBufferString(`{ "reason": "duplicate order" }`)
+	resp := doRequest(t, http.MethodDelete, url, conf.AdminToken, body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// the delete already returned the pack, cancelling must not return it again
+	resp = transitionOrder(t, conf, order.ID, models.OrderCancelled)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status code %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("http://localhost:%s/stock", conf.Port), conf.AdminToken, nil)
+	stock := []models.PackStock{}
+	decodeData(t, resp, &stock)
+	if len(stock) != 1 || stock[0].Quantity != 1 {
+		t.Errorf("expected the 250 pack to be back in stock once, got %+v", stock)
+	}
+}
+
+// amendOrder changes the number of items of the order at version and returns the response
+func amendOrder(t *testing.T, conf config.Configuration, orderID, numberOfItems, version int) *http.Response {
+	t.Helper()
+	url := fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, orderID)
+	body := bytes.NewBufferString(fmt.Sprintf(`{ "number_of_items": %d, "version": %d }`, numberOfItems, version))
+	return doRequest(t, http.MethodPatch, url, conf.AdminToken, body)
+}
+
+func TestAmendOrder(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	// the order's own pack goes back in stock, so it can be packed the same way again
+	setPackStock(t, conf, 250, 1)
+	order := createOrder(t, conf, 1)
+	if order.Version != 1 {
+		t.Fatalf("expected a new order to be at version 1, got %d", order.Version)
+	}
+
+	testcases := []struct {
+		name          string
+		orderID       int
+		numberOfItems int
+		version       int
+		expectedCode  int
+	}{
+		{name: "no version", orderID: order.ID, numberOfItems: 501, expectedCode: http.StatusBadRequest},
+		{name: "amend", orderID: order.ID, numberOfItems: 501, version: 1, expectedCode: http.StatusOK},
+		{name: "stale version", orderID: order.ID, numberOfItems: 10, version: 1, expectedCode: http.StatusConflict},
+		{name: "amend with its own stock", orderID: order.ID, numberOfItems: 1, version: 2, expectedCode: http.StatusOK},
+		{name: "unknown order", orderID: order.ID + 100, numberOfItems: 1, version: 1, expectedCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := amendOrder(t, conf, tc.orderID, tc.numberOfItems, tc.version)
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+
+	resp := doRequest(t, http.MethodGet, fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, order.ID), "", nil)
+	decodeData(t, resp, &order)
+	if order.Version != 3 || order.NumberOfItems != 1 || len(order.Revisions) != 2 {
+		t.Fatalf("expected an order at version 3 with 2 revisions, got %+v", order)
+	}
+	expected := []models.OrderShipping{{PackSize: 500, ShippingPackQuantity: 1}, {PackSize: 250, ShippingPackQuantity: 1}}
+	revision := order.Revisions[1]
+	if revision.Version != 2 || revision.NumberOfItems != 501 || !slices.EqualFunc(revision.Shipping, expected,
+		func(a, b models.OrderShipping) bool {
+			return a.PackSize == b.PackSize && a.ShippingPackQuantity == b.ShippingPackQuantity
+		}) {
+		t.Errorf("expected the second revision to ship 501 items in %v, got %+v", expected, revision)
+	}
+
+	resp = transitionOrder(t, conf, order.ID, models.OrderPacked)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	resp = amendOrder(t, conf, order.ID, 10, 4)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d for a packed order, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	url := fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, order.ID)
+	resp = doRequest(t, http.MethodPatch, url, "", bytes.NewBufferString(`{ "number_of_items": 10, "version": 4 }`))
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d without the admin token, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+// listOrdersPage returns the orders listed at url and the cursor of the next page
+func listOrdersPage(t *testing.T, url string) ([]int, string) {
+	t.Helper()
+	resp := doRequest(t, http.MethodGet, url, "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	orders := []models.Order{}
+	resBody := response{Data: &orders}
+	if err := json.NewDecoder(resp.Body).Decode(&resBody); err != nil {
+		t.Fatalf("unable to decode response: %v", err)
+	}
+
+	items := make([]int, 0, len(orders))
+	for _, order := range orders {
+		items = append(items, order.NumberOfItems)
+	}
+	return items, resBody.NextCursor
+}
+
+func TestListOrdersPages(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	for _, items := range []int{1, 251, 501, 1001, 2001} {
+		createOrder(t, conf, items)
+	}
+	url := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+
+	var pages [][]int
+	cursor := ""
+	for {
+		items, next := listOrdersPage(t, url+"?sort=number_of_items&limit=2&cursor="+cursor)
+		pages = append(pages, items)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	expectedPages := [][]int{{1, 251}, {501, 1001}, {2001}}
+	if !slices.EqualFunc(pages, expectedPages, slices.Equal) {
+		t.Errorf("expected pages %v, got %v", expectedPages, pages)
+	}
+
+	testcases := []struct {
+		name          string
+		query         string
+		expectedItems []int
+	}{
+		{name: "newest first", query: "", expectedItems: []int{2001, 1001, 501, 251, 1}},
+		{name: "most items first", query: "?sort=-number_of_items&limit=3", expectedItems: []int{2001, 1001, 501}},
+		{name: "item range", query: "?min_items=500&max_items=1500", expectedItems: []int{1001, 501}},
+		{name: "pack size", query: "?pack_size=500&sort=number_of_items", expectedItems: []int{251, 501}},
+		{name: "status", query: "?status=packed", expectedItems: []int{}},
+		{name: "created range", query: "?created_from=2000-01-01T00:00:00Z&created_to=2000-01-02T00:00:00Z",
+			expectedItems: []int{}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			items, _ := listOrdersPage(t, url+tc.query)
+			if !slices.Equal(items, tc.expectedItems) {
+				t.Errorf("expected orders with %v items, got %v", tc.expectedItems, items)
+			}
+		})
+	}
+
+	for _, query := range []string{"?cursor=not-a-cursor", "?sort=strategy", "?limit=1000", "?created_from=yesterday"} {
+		resp := doRequest(t, http.MethodGet, url+query, "", nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status code %d for %s, got %d", http.StatusBadRequest, query, resp.StatusCode)
+		}
+	}
+	_, next := listOrdersPage(t, url+"?limit=1")
+	resp := doRequest(t, http.MethodGet, url+"?sort=number_of_items&cursor="+next, "", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d for a cursor of another sort, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+	// cursors are not signed, a value that is not of the sort column is rejected before the query
+	for sort, value := range map[string]string{"number_of_items": "many", "-created_at": "yesterday"} {
+		cursor := fmt.Sprintf(`{"s":%q,"v":%q,"id":1}`, sort, value)
+		query := "?sort=" + sort + "&cursor=" + base64.RawURLEncoding.EncodeToString([]byte(cursor))
+		resp := doRequest(t, http.MethodGet, url+query, "", nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status code %d for cursor %s, got %d", http.StatusBadRequest, cursor, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// ShippingPackRequest adds or changes a pack, UnitCost is in the minor unit
+// of the currency and is kept unchanged when it is omitted from an update
+type ShippingPackRequest struct {
+	Quantity int  `json:"quantity" binding:"required,min=1"`
+	UnitCost *int `json:"unit_cost" binding:"omitempty,min=0"`
+}
+
+type ShipmentFeeRequest struct {
+	ShipmentFee *int `json:"shipment_fee" binding:"required,min=0"`
+}
+
+func (s *Server) GetShippingPacksHandler(c *gin.Context) {
+	packs, err := s.db.GetAvailableShippingPacks(c.Request.Context())
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting shipping packs: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", packs)
+}
+
+func (s *Server) CreateShippingPackHandler(c *gin.Context) {
+	var packRequest ShippingPackRequest
+	err := decode(c, &packRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding shipping pack request: %v", err))
+		badRequest(c, "quantity must be a positive number")
+		return
+	}
+
+	pack := &models.ShippingPack{
+		Quantity: packRequest.Quantity,
+	}
+	if packRequest.UnitCost != nil {
+		pack.UnitCost = *packRequest.UnitCost
+	}
+	err = s.db.CreateShippingPack(c.Request.Context(), pack)
+	if err != nil {
+		s.shippingPackError(c, err)
+		return
+	}
+
+	created(c, "shipping pack created successfully", pack)
+}
+
+func (s *Server) UpdateShippingPackHandler(c *gin.Context) {
+	packID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	var packRequest ShippingPackRequest
+	err := decode(c, &packRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding shipping pack request: %v", err))
+		badRequest(c, "quantity must be a positive number")
+		return
+	}
+
+	pack, err := s.db.GetShippingPack(c.Request.Context(), packID)
+	if err != nil {
+		s.shippingPackError(c, err)
+		return
+	}
+
+	pack.Quantity = packRequest.Quantity
+	if packRequest.UnitCost != nil {
+		pack.UnitCost = *packRequest.UnitCost
+	}
+	err = s.db.UpdateShippingPack(c.Request.Context(), pack)
+	if err != nil {
+		s.shippingPackError(c, err)
+		return
+	}
+
+	ok(c, "shipping pack updated successfully", pack)
+}
+
+func (s *Server) DeleteShippingPackHandler(c *gin.Context) {
+	packID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	catalog, err := s.db.DeleteShippingPack(c.Request.Context(), packID)
+	if err != nil {
+		s.shippingPackError(c, err)
+		return
+	}
+
+	ok(c, "shipping pack deleted successfully", catalog)
+}
+
+// SetShipmentFeeHandler changes the fixed fee charged for every shipment
+func (s *Server) SetShipmentFeeHandler(c *gin.Context) {
+	var feeRequest ShipmentFeeRequest
+	err := decode(c, &feeRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding shipment fee request: %v", err))
+		badRequest(c, "shipment_fee must be zero or a positive number")
+		return
+	}
+
+	catalog, err := s.db.SetShipmentFee(c.Request.Context(), *feeRequest.ShipmentFee)
+	if err != nil {
+		s.shippingPackError(c, err)
+		return
+	}
+
+	ok(c, "shipment fee updated successfully", catalog)
+}
+
+// shippingPackError maps errors from the shipping pack store to a response
+func (s *Server) shippingPackError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		notFound(c)
+	case errors.Is(err, database.ErrDuplicatePackSize):
+		conflict(c, database.ErrDuplicatePackSize.Error())
+	case errors.Is(err, database.ErrLastShippingPack):
+		unprocessableEntity(c, database.ErrLastShippingPack.Error())
+	default:
+		s.logger.Error(fmt.Sprintf("error updating shipping packs: %v", err))
+		internalServerError(c)
+	}
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+// doRequest makes a request to the server, authenticated with token when it is not empty
+func doRequest(t *testing.T, method, url, token string, body io.Reader) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), method, url, body)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make request to server: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Errorf("failed to close response body: %v", err)
+		}
+	})
+
+	return resp
+}
+
+// decodeData decodes the data field of a response into v
+func decodeData(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+	resBody := response{Data: v}
+	if err := json.NewDecoder(resp.Body).Decode(&resBody); err != nil {
+		t.Fatalf("unable to decode response: %v", err)
+	}
+}
+
+func TestShippingPackHandlersRequireAdmin(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	url := fmt.Sprintf("http://localhost:%s/packs", conf.Port)
+	for _, token := range []string{"", "wrong-token"} {
+		resp := doRequest(t, http.MethodGet, url, token, nil)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected status code %d for token %q, got %d", http.StatusUnauthorized, token, resp.StatusCode)
+		}
+	}
+}
+
+func TestShippingPackHandlers(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	url := fmt.Sprintf("http://localhost:%s/packs", conf.Port)
+
+	resp := doRequest(t, http.MethodPost, url, conf.AdminToken, bytes.NewBufferString(`{ "quantity": 750 }`))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	pack := models.ShippingPack{}
+	decodeData(t, resp, &pack)
+	if pack.Quantity != 750 || pack.CatalogVersion != 2 {
+		t.Fatalf("expected a 750 pack in catalog version 2, got %+v", pack)
+	}
+
+	// every change creates a new catalog version, so packs get a new id
+	resp = doRequest(t, http.MethodPut, fmt.Sprintf("%s/%d", url, pack.ID), conf.AdminToken,
+		bytes.NewBufferString(`{ "quantity": 800 }`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	oldID := pack.ID
+	decodeData(t, resp, &pack)
+	if pack.Quantity != 800 || pack.CatalogVersion != 3 {
+		t.Fatalf("expected a 800 pack in catalog version 3, got %+v", pack)
+	}
+
+	testcases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "duplicate pack size",
+			method:       http.MethodPost,
+			body:         `{ "quantity": 500 }`,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "negative pack size",
+			method:       http.MethodPost,
+			body:         `{ "quantity": -1 }`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "resize pack to an existing size",
+			method:       http.MethodPut,
+			path:         fmt.Sprintf("/%d", pack.ID),
+			body:         `{ "quantity": 250 }`,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "resize pack of an inactive catalog",
+			method:       http.MethodPut,
+			path:         fmt.Sprintf("/%d", oldID),
+			body:         `{ "quantity": 900 }`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "retire pack",
+			method:       http.MethodDelete,
+			path:         fmt.Sprintf("/%d", pack.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "retire unknown pack",
+			method:       http.MethodDelete,
+			path:         fmt.Sprintf("/%d", pack.ID),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, tc.method, url+tc.path, conf.AdminToken, bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestDeleteLastShippingPack(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	url := fmt.Sprintf("http://localhost:%s/packs", conf.Port)
+	resp := doRequest(t, http.MethodGet, url, conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	packs := []models.ShippingPack{}
+	decodeData(t, resp, &packs)
+	for len(packs) > 1 {
+		resp := doRequest(t, http.MethodDelete, fmt.Sprintf("%s/%d", url, packs[0].ID), conf.AdminToken, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+		catalog := models.PackCatalog{}
+		decodeData(t, resp, &catalog)
+		packs = catalog.Packs
+	}
+
+	resp = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/%d", url, packs[0].ID), conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected status code %d, got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/boombuler/barcode/code128"
+	"github.com/gin-gonic/gin"
+	"github.com/go-pdf/fpdf"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// sizes of the packing slip, in millimetres
+const (
+	slipMargin        = 20.0
+	slipBarcodeHeight = 18.0
+	// slipBarcodeModule is the width of the narrowest bar of the barcode
+	slipBarcodeModule = 0.5
+	slipRowHeight     = 8.0
+)
+
+// PackingSlipHandler responds with the packing slip of an order as a pdf, for
+// the warehouse to print
+func (s *Server) PackingSlipHandler(c *gin.Context) {
+	orderID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	order, err := s.db.GetOrder(c.Request.Context(), orderID)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		notFound(c)
+		return
+	case err != nil:
+		s.logger.Error(fmt.Sprintf("error getting order: %v", err))
+		internalServerError(c)
+		return
+	case order.DeletedAt != nil:
+		conflict(c, database.ErrOrderDeleted.Error())
+		return
+	}
+
+	slip := &bytes.Buffer{}
+	if err := writePackingSlip(slip, order); err != nil {
+		s.logger.Error(fmt.Sprintf("error rendering packing slip: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="packing-slip-%d.pdf"`, order.ID))
+	c.Data(http.StatusOK, "application/pdf", slip.Bytes())
+}
+
+// writePackingSlip renders the packing slip of an order: the order, a barcode
+// of its id, the packs of each size to ship and the items left over
+func writePackingSlip(w io.Writer, order *models.Order) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(slipMargin, slipMargin, slipMargin)
+	pdf.SetTitle(fmt.Sprintf("Packing slip for order %d", order.ID), true)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Packing slip - order #%d", order.ID), "", 1, "L", false, 0, "")
+	if err := drawBarcode(pdf, strconv.Itoa(order.ID)); err != nil {
+		return err
+	}
+
+	pdf.SetFont("Helvetica", "", 11)
+	details := [][2]string{
+		{"Ordered", order.CreatedAt},
+		{"Status", order.Status},
+		{"Quantity", strconv.Itoa(order.NumberOfItems)},
+	}
+	if order.ExternalReference != nil {
+		// the core fonts are not unicode, the reference is translated to their encoding
+		translate := pdf.UnicodeTranslatorFromDescriptor("")
+		details = append(details, [2]string{"Reference", translate(*order.ExternalReference)})
+	}
+	for _, detail := range details {
+		pdf.CellFormat(40, slipRowHeight, detail[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, slipRowHeight, detail[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(slipRowHeight)
+
+	writeShippingTable(pdf, order)
+
+	return pdf.Output(w)
+}
+
+// drawBarcode draws a code 128 barcode of content with the content printed under it
+func drawBarcode(pdf *fpdf.Fpdf, content string) error {
+	code, err := code128.Encode(content)
+	if err != nil {
+		return fmt.Errorf("could not encode barcode: %w", err)
+	}
+
+	x, y := pdf.GetX(), pdf.GetY()+4
+	pdf.SetFillColor(0, 0, 0)
+	bounds := code.Bounds()
+	for i := bounds.Min.X; i < bounds.Max.X; i++ {
+		if r, _, _, _ := code.At(i, 0).RGBA(); r == 0 {
+			pdf.Rect(x+float64(i)*slipBarcodeModule, y, slipBarcodeModule, slipBarcodeHeight, "F")
+		}
+	}
+
+	pdf.SetXY(x, y+slipBarcodeHeight)
+	pdf.SetFont("Courier", "", 10)
+	pdf.CellFormat(float64(bounds.Dx())*slipBarcodeModule, 6, content, "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	return nil
+}
+
+// writeShippingTable writes the packs of each size, the items they hold and
+// the items left over once the order is packed
+func writeShippingTable(pdf *fpdf.Fpdf, order *models.Order) {
+	widths := []float64{50, 40, 40}
+	header := []string{"Pack size", "Packs", "Items"}
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.SetFillColor(230, 230, 230)
+	for i, name := range header {
+		pdf.CellFormat(widths[i], slipRowHeight, name, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 11)
+	packs, packed := 0, 0
+	for _, s := range order.Shipping {
+		items := s.PackSize * s.ShippingPackQuantity
+		packs += s.ShippingPackQuantity
+		packed += items
+		for i, value := range []int{s.PackSize, s.ShippingPackQuantity, items} {
+			pdf.CellFormat(widths[i], slipRowHeight, strconv.Itoa(value), "1", 0, "R", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(widths[0], slipRowHeight, "Total", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[1], slipRowHeight, strconv.Itoa(packs), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[2], slipRowHeight, strconv.Itoa(packed), "1", 1, "R", false, 0, "")
+	pdf.Ln(slipRowHeight / 2)
+	pdf.CellFormat(0, slipRowHeight, fmt.Sprintf("Leftover: %d items", packed-order.NumberOfItems), "", 1, "L", false, 0, "")
+}
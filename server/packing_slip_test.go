@@ -0,0 +1,63 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+func TestWritePackingSlip(t *testing.T) {
+	reference := "PO-ÅÉ-1"
+	order := &models.Order{ID: 42, NumberOfItems: 501, Status: models.OrderPending, ExternalReference: &reference,
+		CreatedAt: "2024-01-01T00:00:00Z",
+		Shipping:  []models.OrderShipping{{PackSize: 500, ShippingPackQuantity: 1}, {PackSize: 250, ShippingPackQuantity: 1}}}
+
+	slip := &bytes.Buffer{}
+	if err := writePackingSlip(slip, order); err != nil {
+		t.Fatalf("failed to write packing slip: %v", err)
+	}
+	if !bytes.HasPrefix(slip.Bytes(), []byte("%PDF-")) || !bytes.HasSuffix(bytes.TrimSpace(slip.Bytes()), []byte("%%EOF")) {
+		t.Errorf("expected a pdf document, got %q", slip.String())
+	}
+}
+
+func TestPackingSlipHandler(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+	order := createOrder(t, conf, 501)
+	ordersURL := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+
+	resp := doRequest(t, http.MethodGet, fmt.Sprintf("%s/%d/packing-slip.pdf", ordersURL, order.ID), "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/pdf" {
+		t.Errorf("expected a pdf, got content type %s", contentType)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	if !bytes.HasPrefix(body, []byte("%PDF-")) {
+		t.Errorf("expected a pdf document, got %q", body)
+	}
+
+	resp = doRequest(t, http.MethodGet, ordersURL+"/1000/packing-slip.pdf", "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d for an unknown order, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/%d", ordersURL, order.ID), conf.AdminToken,
+		bytes.NewBufferString(`{ "reason": "duplicate order" }`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d deleting the order, got %d", http.StatusOK, resp.StatusCode)
+	}
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("%s/%d/packing-slip.pdf", ordersURL, order.ID), "", nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d for a deleted order, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
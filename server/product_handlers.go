@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+type CreateProductRequest struct {
+	SKU       string `json:"sku" binding:"required,max=64"`
+	Name      string `json:"name" binding:"required,max=255"`
+	PackSizes []int  `json:"pack_sizes" binding:"omitempty,unique,dive,min=1"`
+}
+
+type ProductPacksRequest struct {
+	PackSizes []int `json:"pack_sizes" binding:"required,min=1,unique,dive,min=1"`
+}
+
+func (s *Server) CreateProductHandler(c *gin.Context) {
+	var productRequest CreateProductRequest
+	err := decode(c, &productRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding product request: %v", err))
+		badRequest(c, "")
+		return
+	}
+
+	product := &models.Product{
+		SKU:  productRequest.SKU,
+		Name: productRequest.Name,
+	}
+	err = s.db.CreateProduct(c.Request.Context(), product, productRequest.PackSizes)
+	if errors.Is(err, database.ErrDuplicateSKU) {
+		conflict(c, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error creating product: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	created(c, "product created successfully", product)
+}
+
+func (s *Server) GetProductsHandler(c *gin.Context) {
+	products, err := s.db.GetProducts(c.Request.Context())
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting products: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", products)
+}
+
+func (s *Server) GetProductHandler(c *gin.Context) {
+	productID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	product, err := s.db.GetProduct(c.Request.Context(), productID)
+	if errors.Is(err, database.ErrNotFound) {
+		notFound(c)
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting product: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", product)
+}
+
+// SetProductPacksHandler replaces the pack sizes of a product with a new catalog version
+func (s *Server) SetProductPacksHandler(c *gin.Context) {
+	productID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	var packsRequest ProductPacksRequest
+	err := decode(c, &packsRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding product packs request: %v", err))
+		badRequest(c, "pack_sizes must be a list of unique positive numbers")
+		return
+	}
+
+	catalog, err := s.db.SetProductPacks(c.Request.Context(), productID, packsRequest.PackSizes)
+	if errors.Is(err, database.ErrNotFound) {
+		notFound(c)
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error setting product packs: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "product packs updated successfully", catalog)
+}
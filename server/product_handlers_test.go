@@ -0,0 +1,143 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/spankie/gymshark/config"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// createProduct creates a product through the api and returns it
+func createProduct(t *testing.T, conf config.Configuration, body string) models.Product {
+	t.Helper()
+	url := fmt.Sprintf("http://localhost:%s/products", conf.Port)
+	resp := doRequest(t, http.MethodPost, url, conf.AdminToken, bytes.NewBufferString(body))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	product := models.Product{}
+	decodeData(t, resp, &product)
+	return product
+}
+
+func TestProductHandlers(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	url := fmt.Sprintf("http://localhost:%s/products", conf.Port)
+
+	product := createProduct(t, conf, `{ "sku": "TEE-1", "name": "T-Shirt", "pack_sizes": [10, 50] }`)
+	if product.Catalog == nil || len(product.Catalog.Packs) != 2 || *product.Catalog.ProductID != product.ID {
+		t.Fatalf("expected product to have its own catalog, got %+v", product.Catalog)
+	}
+
+	resp := doRequest(t, http.MethodPost, url, conf.AdminToken,
+		bytes.NewBufferString(`{ "sku": "TEE-1", "name": "T-Shirt" }`))
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodPut, fmt.Sprintf("%s/%d/packs", url, product.ID), conf.AdminToken,
+		bytes.NewBufferString(`{ "pack_sizes": [20] }`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	catalog := models.PackCatalog{}
+	decodeData(t, resp, &catalog)
+	if catalog.Version == product.Catalog.Version || len(catalog.Packs) != 1 {
+		t.Errorf("expected a new catalog version with one pack, got %+v", catalog)
+	}
+
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("%s/%d", url, product.ID), "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	decodeData(t, resp, &product)
+	if product.Catalog == nil || product.Catalog.Version != catalog.Version {
+		t.Errorf("expected product catalog to be version %d, got %+v", catalog.Version, product.Catalog)
+	}
+
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("%s/%d", url, 100), "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestCreateOrderWithLines(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	tee := createProduct(t, conf, `{ "sku": "TEE-1", "name": "T-Shirt", "pack_sizes": [10, 50] }`)
+	// products without their own packs use the global catalog
+	sock := createProduct(t, conf, `{ "sku": "SOCK-1", "name": "Socks" }`)
+
+	url := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+	body := fmt.Sprintf(`{ "lines": [{ "product_id": %d, "quantity": 65 }, { "product_id": %d, "quantity": 501 }] }`,
+		tee.ID, sock.ID)
+	resp := doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(body))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	order := models.Order{}
+	decodeData(t, resp, &order)
+
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("%s/%d", url, order.ID), "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	decodeData(t, resp, &order)
+
+	if order.NumberOfItems != 566 {
+		t.Errorf("expected number of items to be 566, got %d", order.NumberOfItems)
+	}
+	if len(order.Lines) != 2 {
+		t.Fatalf("expected 2 order lines, got %d", len(order.Lines))
+	}
+
+	// 65 t-shirts ship in a 50 and two 10 packs
+	if order.Lines[0].CatalogVersion != tee.Catalog.Version || len(order.Lines[0].Shipping) != 2 {
+		t.Errorf("expected t-shirts to ship with their own catalog, got %+v", order.Lines[0])
+	}
+	// 501 socks ship in a 500 and a 250 pack
+	if order.Lines[1].CatalogVersion != *order.CatalogVersion || len(order.Lines[1].Shipping) != 2 {
+		t.Errorf("expected socks to ship with the global catalog, got %+v", order.Lines[1])
+	}
+	if len(order.Shipping) != 4 {
+		t.Errorf("expected the order total to have 4 pack sizes, got %+v", order.Shipping)
+	}
+
+	testcases := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "unknown product",
+			body:         `{ "lines": [{ "product_id": 100, "quantity": 1 }] }`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "duplicate product",
+			body:         fmt.Sprintf(`{ "lines": [{ "product_id": %[1]d, "quantity": 1 }, { "product_id": %[1]d, "quantity": 2 }] }`, tee.ID),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "both number of items and lines",
+			body:         fmt.Sprintf(`{ "number_of_items": 1, "lines": [{ "product_id": %d, "quantity": 1 }] }`, tee.ID),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/services"
+)
+
+type QuoteRequest struct {
+	NumberOfItems int    `json:"number_of_items" binding:"required,min=1"`
+	PackSizes     []int  `json:"pack_sizes" binding:"omitempty,unique,dive,min=1"`
+	Strategy      string `json:"strategy"`
+	Explain       int    `json:"explain" binding:"omitempty,min=1,max=10"`
+}
+
+// QuoteHandler calculates how an order would be packed without creating it
+func (s *Server) QuoteHandler(c *gin.Context) {
+	var quoteRequest QuoteRequest
+	err := decode(c, &quoteRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding quote request: %v", err))
+		badRequest(c, "")
+		return
+	}
+
+	quote, err := s.orderService.Quote(c.Request.Context(), services.QuoteOptions{
+		NumberOfItems: quoteRequest.NumberOfItems,
+		PackSizes:     quoteRequest.PackSizes,
+		Strategy:      quoteRequest.Strategy,
+		Explain:       quoteRequest.Explain,
+	})
+	if err != nil {
+		s.packingError(c, err)
+		return
+	}
+
+	ok(c, "quote calculated successfully", quote)
+}
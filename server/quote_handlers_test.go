@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/spankie/gymshark/services"
+)
+
+func TestQuoteHandler(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	testcases := []struct {
+		name          string
+		body          string
+		expectedCode  int
+		expectedQuote services.Quote
+	}{
+		{
+			name:         "quote with the active catalog",
+			body:         `{ "number_of_items": 501 }`,
+			expectedCode: http.StatusOK,
+			expectedQuote: services.Quote{
+				Packs:      []services.PackCount{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}},
+				TotalItems: 750,
+				Leftover:   249,
+				PackCount:  2,
+			},
+		},
+		{
+			name:         "quote with explicit pack sizes",
+			body:         `{ "number_of_items": 10, "pack_sizes": [3, 7] }`,
+			expectedCode: http.StatusOK,
+			expectedQuote: services.Quote{
+				Packs:      []services.PackCount{{PackSize: 7, Quantity: 1}, {PackSize: 3, Quantity: 1}},
+				TotalItems: 10,
+				Leftover:   0,
+				PackCount:  2,
+			},
+		},
+		{
+			name:         "duplicate pack sizes",
+			body:         `{ "number_of_items": 10, "pack_sizes": [3, 3] }`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative pack size",
+			body:         `{ "number_of_items": 10, "pack_sizes": [-3] }`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "empty number of items",
+			body:         `{ "number_of_items": 0 }`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			url := fmt.Sprintf("http://localhost:%s/quotes", conf.Port)
+			resp := doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Fatalf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+
+			quote := services.Quote{}
+			decodeData(t, resp, &quote)
+			if !slices.Equal(quote.Packs, tc.expectedQuote.Packs) {
+				t.Errorf("expected packs to be %v but got %v", tc.expectedQuote.Packs, quote.Packs)
+			}
+			if quote.TotalItems != tc.expectedQuote.TotalItems || quote.Leftover != tc.expectedQuote.Leftover ||
+				quote.PackCount != tc.expectedQuote.PackCount {
+				t.Errorf("expected quote %+v but got %+v", tc.expectedQuote, quote)
+			}
+		})
+	}
+}
+
+func TestExplainPacking(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	url := fmt.Sprintf("http://localhost:%s/quotes", conf.Port)
+	resp := doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(`{ "number_of_items": 501, "explain": 11 }`))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d for too many alternatives, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(`{ "number_of_items": 501, "explain": 3 }`))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	quote := services.Quote{}
+	decodeData(t, resp, &quote)
+	if quote.Explanation == nil || len(quote.Explanation.Alternatives) != 3 {
+		t.Fatalf("expected an explanation with 3 alternatives, got %+v", quote.Explanation)
+	}
+	if quote.Explanation.Reason != "lower overshoot" {
+		t.Errorf("expected reason %q, got %q", "lower overshoot", quote.Explanation.Reason)
+	}
+	if !slices.Equal(quote.Explanation.Alternatives[0].Packs, quote.Packs) {
+		t.Errorf("expected the first alternative %v to be the quoted packs %v",
+			quote.Explanation.Alternatives[0].Packs, quote.Packs)
+	}
+
+	url = fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+	resp = doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(`{ "number_of_items": 501, "explain": 2 }`))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	order := struct {
+		ID           int                    `json:"id"`
+		Explanations []services.Explanation `json:"explanations"`
+	}{}
+	decodeData(t, resp, &order)
+	if order.ID == 0 || len(order.Explanations) != 1 || len(order.Explanations[0].Alternatives) != 2 {
+		t.Errorf("expected an order with an explanation of 2 alternatives, got %+v", order)
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/services"
+)
+
+// defaultRepackResultsLimit is the number of repack results in a page when the query has no limit
+const defaultRepackResultsLimit = 50
+
+// RepackJobRequest creates a repack job that packs the orders it selects again
+// with the packs of the catalog at CatalogVersion. Mode is what_if, the
+// default, or apply. The filter selects pending orders unless it has a Status.
+type RepackJobRequest struct {
+	CatalogVersion int        `json:"catalog_version" binding:"required,min=1"`
+	Mode           string     `json:"mode" binding:"omitempty,oneof=what_if apply"`
+	CreatedFrom    *time.Time `json:"created_from"`
+	CreatedTo      *time.Time `json:"created_to"`
+	MinItems       *int       `json:"min_items" binding:"omitempty,min=1"`
+	MaxItems       *int       `json:"max_items" binding:"omitempty,min=1"`
+	PackSize       *int       `json:"pack_size" binding:"omitempty,min=1"`
+	Status         string     `json:"status" binding:"omitempty,oneof=pending packed shipped delivered cancelled"`
+	CustomerID     *int       `json:"customer_id" binding:"omitempty,min=1"`
+}
+
+func (r RepackJobRequest) job() *models.RepackJob {
+	return &models.RepackJob{
+		CatalogVersion: r.CatalogVersion,
+		Mode:           r.Mode,
+		Filter: models.RepackFilter{
+			CreatedFrom: r.CreatedFrom,
+			CreatedTo:   r.CreatedTo,
+			MinItems:    r.MinItems,
+			MaxItems:    r.MaxItems,
+			PackSize:    r.PackSize,
+			Status:      r.Status,
+			CustomerID:  r.CustomerID,
+		},
+	}
+}
+
+// RepackResultsQuery pages the results of a repack job. After is the
+// next_cursor of the previous page and ChangedOnly leaves out the orders
+// whose packing is the same.
+type RepackResultsQuery struct {
+	After       int  `form:"after" binding:"omitempty,min=0"`
+	Limit       int  `form:"limit" binding:"omitempty,min=1,max=200"`
+	ChangedOnly bool `form:"changed_only"`
+}
+
+// CreateRepackJobHandler creates a repack job and starts it in the
+// background, the job is polled until it is completed
+func (s *Server) CreateRepackJobHandler(c *gin.Context) {
+	var repackRequest RepackJobRequest
+	if err := decode(c, &repackRequest); err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding repack job request: %v", err))
+		badRequest(c, err.Error())
+		return
+	}
+
+	job := repackRequest.job()
+	err := s.orderService.CreateRepackJob(c.Request.Context(), job)
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrUnknownCatalog):
+		unprocessableEntity(c, err.Error())
+		return
+	case errors.Is(err, services.ErrUnknownRepackMode):
+		badRequest(c, err.Error())
+		return
+	default:
+		s.logger.Error(fmt.Sprintf("error creating repack job: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	s.startRepackJob(c, job.ID, "repack job created successfully")
+}
+
+// ResumeRepackJobHandler starts a repack job again after the last batch it
+// saved, the job must have failed or stopped running
+func (s *Server) ResumeRepackJobHandler(c *gin.Context) {
+	jobID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	s.startRepackJob(c, jobID, "repack job resumed successfully")
+}
+
+// startRepackJob claims a repack job and runs it in the background, it
+// responds with the job once it is claimed
+func (s *Server) startRepackJob(c *gin.Context, jobID int, message string) {
+	job, err := s.orderService.ClaimRepackJob(c.Request.Context(), jobID)
+	switch {
+	case err == nil:
+	case errors.Is(err, database.ErrNotFound):
+		notFound(c)
+		return
+	case errors.Is(err, database.ErrRepackJobNotResumable):
+		conflict(c, err.Error())
+		return
+	default:
+		s.logger.Error(fmt.Sprintf("error claiming repack job: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	// the job outlives the request, a job stopped with the server is resumed later
+	running := *job
+	go func() {
+		if err := s.orderService.RunRepackJob(context.Background(), &running); err != nil {
+			s.logger.Error(fmt.Sprintf("error running repack job %d: %v", running.ID, err))
+		}
+	}()
+
+	respondJSON(c, http.StatusAccepted, message, "", job)
+}
+
+func (s *Server) GetRepackJobsHandler(c *gin.Context) {
+	jobs, err := s.db.GetRepackJobs(c.Request.Context())
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting repack jobs: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", jobs)
+}
+
+func (s *Server) GetRepackJobHandler(c *gin.Context) {
+	jobID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+
+	job, err := s.db.GetRepackJob(c.Request.Context(), jobID)
+	if errors.Is(err, database.ErrNotFound) {
+		notFound(c)
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting repack job: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", job)
+}
+
+// GetRepackResultsHandler responds with a page of the old and new packing of
+// the orders a repack job processed
+func (s *Server) GetRepackResultsHandler(c *gin.Context) {
+	jobID, valid := intParam(c, "id")
+	if !valid {
+		return
+	}
+	var resultsQuery RepackResultsQuery
+	if err := c.ShouldBindQuery(&resultsQuery); err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding repack results query: %v", err))
+		badRequest(c, err.Error())
+		return
+	}
+	limit := resultsQuery.Limit
+	if limit == 0 {
+		limit = defaultRepackResultsLimit
+	}
+
+	ctx := c.Request.Context()
+	if _, err := s.db.GetRepackJob(ctx, jobID); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			notFound(c)
+			return
+		}
+		s.logger.Error(fmt.Sprintf("error getting repack job: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	results, err := s.db.GetRepackResults(ctx, jobID, resultsQuery.After, limit, resultsQuery.ChangedOnly)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting repack results: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	nextCursor := ""
+	if len(results) == limit {
+		nextCursor = strconv.Itoa(results[len(results)-1].OrderID)
+	}
+	okPage(c, "successful", results, nextCursor)
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/spankie/gymshark/config"
+	"github.com/spankie/gymshark/database/models"
+)
+
+func TestRepackJobs(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+	url := fmt.Sprintf("http://localhost:%s", conf.Port)
+
+	// both orders ship in a 500 pack, a 300 pack ships the first one better
+	better := createOrder(t, conf, 251)
+	createOrder(t, conf, 500)
+	resp := doRequest(t, http.MethodPost, url+"/packs", conf.AdminToken, bytes.NewBufferString(`{ "quantity": 300 }`))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	whatIf := startRepackJob(t, conf, `{ "catalog_version": 2 }`)
+	if whatIf.Mode != models.RepackWhatIf || whatIf.Total != 2 || whatIf.Filter.Status != models.OrderPending {
+		t.Errorf("expected a what if job of the 2 pending orders, got %+v", whatIf)
+	}
+	whatIf = waitForRepackJob(t, conf, whatIf.ID)
+	if whatIf.Processed != 2 || whatIf.Changed != 1 || whatIf.Failed != 0 || whatIf.OvershootChange != -200 ||
+		whatIf.PacksChange != 0 {
+		t.Errorf("expected 1 of 2 orders to ship 200 items less, got %+v", whatIf)
+	}
+
+	resp = doRequest(t, http.MethodGet, fmt.Sprintf("%s/repack-jobs/%d/results?changed_only=true", url, whatIf.ID),
+		conf.AdminToken, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	results := []models.RepackResult{}
+	decodeData(t, resp, &results)
+	if len(results) != 1 || results[0].OrderID != better.ID || results[0].Applied ||
+		results[0].OldOvershoot != 249 || *results[0].NewOvershoot != 49 {
+		t.Fatalf("expected the 251 order to go from 249 to 49 items over, got %+v", results)
+	}
+	if order := getOrder(t, conf, better.ID); order.Version != better.Version {
+		t.Errorf("expected a what if job to leave the order at version %d, got %d", better.Version, order.Version)
+	}
+
+	apply := waitForRepackJob(t, conf, startRepackJob(t, conf, `{ "catalog_version": 2, "mode": "apply" }`).ID)
+	if apply.Status != models.RepackCompleted || apply.Changed != 1 || apply.Failed != 0 {
+		t.Errorf("expected the apply job to change 1 order, got %+v", apply)
+	}
+	order := getOrder(t, conf, better.ID)
+	if order.Version != better.Version+1 || len(order.Shipping) != 1 || order.Shipping[0].PackSize != 300 ||
+		*order.CatalogVersion != 2 {
+		t.Errorf("expected the order to be shipped in a 300 pack of catalog 2, got %+v", order)
+	}
+
+	testcases := []struct {
+		name         string
+		method       string
+		path         string
+		token        string
+		body         string
+		expectedCode int
+	}{
+		{name: "resume a completed job", method: http.MethodPost, path: fmt.Sprintf("/%d/resume", apply.ID),
+			token: conf.AdminToken, expectedCode: http.StatusConflict},
+		{name: "resume an unknown job", method: http.MethodPost, path: "/999/resume", token: conf.AdminToken,
+			expectedCode: http.StatusNotFound},
+		{name: "unknown catalog", method: http.MethodPost, token: conf.AdminToken,
+			body: `{ "catalog_version": 99 }`, expectedCode: http.StatusUnprocessableEntity},
+		{name: "unknown mode", method: http.MethodPost, token: conf.AdminToken,
+			body: `{ "catalog_version": 2, "mode": "maybe" }`, expectedCode: http.StatusBadRequest},
+		{name: "list jobs", method: http.MethodGet, token: conf.AdminToken, expectedCode: http.StatusOK},
+		{name: "unknown job results", method: http.MethodGet, path: "/999/results", token: conf.AdminToken,
+			expectedCode: http.StatusNotFound},
+		{name: "without the admin token", method: http.MethodGet, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, tc.method, url+"/repack-jobs"+tc.path, tc.token, bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func startRepackJob(t *testing.T, conf config.Configuration, body string) models.RepackJob {
+	t.Helper()
+	resp := doRequest(t, http.MethodPost, fmt.Sprintf("http://localhost:%s/repack-jobs", conf.Port), conf.AdminToken,
+		bytes.NewBufferString(body))
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status code %d starting the repack job, got %d", http.StatusAccepted, resp.StatusCode)
+	}
+	job := models.RepackJob{}
+	decodeData(t, resp, &job)
+
+	return job
+}
+
+// waitForRepackJob polls a repack job until it is finished
+func waitForRepackJob(t *testing.T, conf config.Configuration, jobID int) models.RepackJob {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		resp := doRequest(t, http.MethodGet, fmt.Sprintf("http://localhost:%s/repack-jobs/%d", conf.Port, jobID),
+			conf.AdminToken, nil)
+		job := models.RepackJob{}
+		decodeData(t, resp, &job)
+		if job.Status == models.RepackCompleted || job.Status == models.RepackFailed {
+			return job
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("repack job %d did not finish", jobID)
+
+	return models.RepackJob{}
+}
+
+func getOrder(t *testing.T, conf config.Configuration, orderID int) models.Order {
+	t.Helper()
+	resp := doRequest(t, http.MethodGet, fmt.Sprintf("http://localhost:%s/orders/%d", conf.Port, orderID), "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d getting the order, got %d", http.StatusOK, resp.StatusCode)
+	}
+	order := models.Order{}
+	decodeData(t, resp, &order)
+
+	return order
+}
@@ -13,7 +13,7 @@ func (s *Server) setupCorsConfig(r *gin.Engine) {
 		corsConfig := cors.Config{
 			AllowOrigins:     []string{s.config.FrontendURL},
 			AllowMethods:     []string{"PUT", "PATCH", "POST", "GET", "OPTIONS", "DELETE"},
-			AllowHeaders:     []string{"Origin", "Content-Type", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers"},
+			AllowHeaders:     []string{"Origin", "Content-Type", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Authorization"},
 			ExposeHeaders:    []string{"Content-Length", "Content-Type"},
 			AllowCredentials: true,
 			MaxAge:           12 * time.Hour,
@@ -33,9 +33,59 @@ func (s *Server) RegisterRoutes() http.Handler {
 	r.GET("/health", s.healthHandler)
 
 	r.POST("/orders", s.CreateOrderHandler)
+	r.POST("/orders/import", s.requireAdmin, s.ImportOrdersHandler)
+	r.GET("/orders/export", s.requireAdmin, s.ExportOrdersHandler)
 	r.GET("/orders/:id", s.GetOrderHandler)
+	r.PATCH("/orders/:id", s.requireAdmin, s.AmendOrderHandler)
+	r.GET("/orders/:id/packing-slip.pdf", s.PackingSlipHandler)
+	r.POST("/orders/:id/transitions", s.requireAdmin, s.TransitionOrderHandler)
+	r.DELETE("/orders/:id", s.requireAdmin, s.DeleteOrderHandler)
+	r.POST("/orders/:id/restore", s.requireAdmin, s.RestoreOrderHandler)
 
 	r.GET("/orders", s.GetAllOrdersHandler)
 
+	r.POST("/quotes", s.QuoteHandler)
+
+	r.GET("/analytics/packing", s.requireAdmin, s.PackingAnalyticsHandler)
+	r.GET("/analytics/catalog-recommendations", s.requireAdmin, s.CatalogRecommendationsHandler)
+
+	packs := r.Group("/packs", s.requireAdmin)
+	packs.GET("", s.GetShippingPacksHandler)
+	packs.POST("", s.CreateShippingPackHandler)
+	packs.PUT("/shipment-fee", s.SetShipmentFeeHandler)
+	packs.PUT("/:id", s.UpdateShippingPackHandler)
+	packs.DELETE("/:id", s.DeleteShippingPackHandler)
+
+	r.GET("/products", s.GetProductsHandler)
+	r.GET("/products/:id", s.GetProductHandler)
+	r.POST("/products", s.requireAdmin, s.CreateProductHandler)
+	r.PUT("/products/:id/packs", s.requireAdmin, s.SetProductPacksHandler)
+
+	customers := r.Group("/customers", s.requireAdmin)
+	customers.GET("", s.GetCustomersHandler)
+	customers.POST("", s.CreateCustomerHandler)
+	customers.GET("/:id", s.GetCustomerHandler)
+	customers.PUT("/:id", s.UpdateCustomerHandler)
+	customers.DELETE("/:id", s.DeleteCustomerHandler)
+	customers.GET("/:id/orders", s.GetCustomerOrdersHandler)
+
+	stock := r.Group("/stock", s.requireAdmin)
+	stock.GET("", s.GetPackStockHandler)
+	stock.PUT("/:pack_size", s.SetPackStockHandler)
+	stock.DELETE("/:pack_size", s.DeletePackStockHandler)
+
+	catalogs := r.Group("/catalogs", s.requireAdmin)
+	catalogs.GET("", s.GetPackCatalogsHandler)
+	catalogs.GET("/analysis", s.AnalyzePackCatalogHandler)
+	catalogs.GET("/:version", s.GetPackCatalogHandler)
+	catalogs.POST("/:version/activate", s.ActivatePackCatalogHandler)
+
+	repack := r.Group("/repack-jobs", s.requireAdmin)
+	repack.GET("", s.GetRepackJobsHandler)
+	repack.POST("", s.CreateRepackJobHandler)
+	repack.GET("/:id", s.GetRepackJobHandler)
+	repack.GET("/:id/results", s.GetRepackResultsHandler)
+	repack.POST("/:id/resume", s.ResumeRepackJobHandler)
+
 	return r
 }
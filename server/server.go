@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -23,6 +24,8 @@ type response struct {
 	Data    interface{} `json:"data"`
 	Message string      `json:"message"`
 	Error   string      `json:"error"`
+	// NextCursor fetches the next page of a list, it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func NewServer(config *config.Configuration, dbService database.Service,
@@ -60,6 +63,18 @@ func decode(c *gin.Context, v interface{}) error {
 	return nil
 }
 
+// intParam parses a numeric path parameter, it responds with a bad request and
+// returns false when the parameter is not a number
+func intParam(c *gin.Context, name string) (int, bool) {
+	value, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		badRequest(c, err.Error())
+		return 0, false
+	}
+
+	return value, true
+}
+
 func respondJSON(c *gin.Context, status int, message, err string, data interface{}) {
 	c.JSON(status, response{
 		Data:    data,
@@ -72,6 +87,15 @@ func ok(c *gin.Context, message string, data interface{}) {
 	respondJSON(c, http.StatusOK, message, "", data)
 }
 
+// okPage responds with a page of a list and the cursor of the next page
+func okPage(c *gin.Context, message string, data interface{}, nextCursor string) {
+	c.JSON(http.StatusOK, response{
+		Data:       data,
+		Message:    message,
+		NextCursor: nextCursor,
+	})
+}
+
 func created(c *gin.Context, message string, data interface{}) {
 	respondJSON(c, http.StatusCreated, message, "", data)
 }
@@ -90,3 +114,29 @@ func internalServerError(c *gin.Context) {
 func notFound(c *gin.Context) {
 	respondJSON(c, http.StatusNotFound, "", "bad request", nil)
 }
+
+func unauthorized(c *gin.Context) {
+	respondJSON(c, http.StatusUnauthorized, "", "unauthorized", nil)
+}
+
+func conflict(c *gin.Context, err string) {
+	respondJSON(c, http.StatusConflict, "", err, nil)
+}
+
+func requestEntityTooLarge(c *gin.Context, err string) {
+	respondJSON(c, http.StatusRequestEntityTooLarge, "", err, nil)
+}
+
+func unprocessableEntity(c *gin.Context, err string) {
+	respondJSON(c, http.StatusUnprocessableEntity, "", err, nil)
+}
+
+// statusClientClosedRequest is the status logged when the client goes away
+// before the response, as nginx does
+const statusClientClosedRequest = 499
+
+// clientClosedRequest ends a request whose client is gone, there is no one to
+// respond to but the status shows up in the logs and metrics
+func clientClosedRequest(c *gin.Context) {
+	c.AbortWithStatus(statusClientClosedRequest)
+}
@@ -19,7 +19,15 @@ func setupHTTPServer(t *testing.T, conf *config.Configuration, dbService databas
 	t.Helper()
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
-	orderService := services.NewOrderService(dbService, logger)
+	strategy, err := services.GetPackingStrategy(conf.PackingStrategy)
+	if err != nil {
+		t.Fatalf("error getting packing strategy: %v", err)
+	}
+	orderService := services.NewOrderService(dbService, logger, strategy, services.Limits{
+		MaxItems:       conf.MaxOrderItems,
+		MaxTableSize:   conf.MaxPackingTable,
+		PackingTimeout: conf.PackingTimeout,
+	})
 
 	server := NewServer(conf, dbService, orderService, logger)
 	httpServer := server.NewHTTPServer()
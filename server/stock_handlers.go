@@ -0,0 +1,82 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+type PackStockRequest struct {
+	Quantity *int `json:"quantity" binding:"required,min=0"`
+}
+
+func (s *Server) GetPackStockHandler(c *gin.Context) {
+	stock, err := s.db.GetPackStock(c.Request.Context())
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error getting pack stock: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "successful", stock)
+}
+
+func (s *Server) SetPackStockHandler(c *gin.Context) {
+	packSize, valid := packSizeParam(c)
+	if !valid {
+		return
+	}
+
+	var stockRequest PackStockRequest
+	err := decode(c, &stockRequest)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Error decoding pack stock request: %v", err))
+		badRequest(c, "quantity must be zero or a positive number")
+		return
+	}
+
+	stock := &models.PackStock{PackSize: packSize, Quantity: *stockRequest.Quantity}
+	err = s.db.SetPackStock(c.Request.Context(), stock)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error setting pack stock: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "pack stock updated successfully", stock)
+}
+
+func (s *Server) DeletePackStockHandler(c *gin.Context) {
+	packSize, valid := packSizeParam(c)
+	if !valid {
+		return
+	}
+
+	err := s.db.DeletePackStock(c.Request.Context(), packSize)
+	if errors.Is(err, database.ErrNotFound) {
+		notFound(c)
+		return
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("error deleting pack stock: %v", err))
+		internalServerError(c)
+		return
+	}
+
+	ok(c, "pack stock is no longer tracked", nil)
+}
+
+// packSizeParam reads the pack size from the path, it responds with a bad
+// request when the size is not a positive number
+func packSizeParam(c *gin.Context) (int, bool) {
+	packSize, valid := intParam(c, "pack_size")
+	if valid && packSize < 1 {
+		badRequest(c, "pack size must be a positive number")
+		return 0, false
+	}
+
+	return packSize, valid
+}
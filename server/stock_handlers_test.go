@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/spankie/gymshark/config"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// setPackStock sets the stock of a pack size
+func setPackStock(t *testing.T, conf config.Configuration, size, quantity int) {
+	t.Helper()
+	url := fmt.Sprintf("http://localhost:%s/stock/%d", conf.Port, size)
+	body := bytes.NewBufferString(fmt.Sprintf(`{ "quantity": %d }`, quantity))
+	resp := doRequest(t, http.MethodPut, url, conf.AdminToken, body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestPackStockHandlers(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	url := fmt.Sprintf("http://localhost:%s/stock", conf.Port)
+	testcases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "set stock",
+			method:       http.MethodPut,
+			path:         "/250",
+			body:         `{ "quantity": 3 }`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "negative stock",
+			method:       http.MethodPut,
+			path:         "/250",
+			body:         `{ "quantity": -1 }`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing stock",
+			method:       http.MethodPut,
+			path:         "/250",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid pack size",
+			method:       http.MethodPut,
+			path:         "/0",
+			body:         `{ "quantity": 3 }`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "stop tracking stock",
+			method:       http.MethodDelete,
+			path:         "/250",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "stop tracking untracked stock",
+			method:       http.MethodDelete,
+			path:         "/250",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, tc.method, url+tc.path, conf.AdminToken, bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+		})
+	}
+
+	resp := doRequest(t, http.MethodGet, url, "", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestCreateOrderWithStock(t *testing.T) { //nolint:cyclop
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	setPackStock(t, conf, 250, 1)
+	setPackStock(t, conf, 500, 0)
+
+	testcases := []struct {
+		name          string
+		body          string
+		expectedCode  int
+		expectedPacks map[int]int
+	}{
+		{
+			name:          "pack in stock",
+			body:          `{ "number_of_items": 1 }`,
+			expectedCode:  http.StatusCreated,
+			expectedPacks: map[int]int{250: 1},
+		},
+		{
+			name:          "smaller packs out of stock",
+			body:          `{ "number_of_items": 1 }`,
+			expectedCode:  http.StatusCreated,
+			expectedPacks: map[int]int{1000: 1},
+		},
+		{
+			name:         "no exact packing in stock",
+			body:         `{ "number_of_items": 250, "strategy": "exact" }`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	url := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(tc.body))
+			if resp.StatusCode != tc.expectedCode {
+				t.Fatalf("expected status code %d, got %d", tc.expectedCode, resp.StatusCode)
+			}
+			if tc.expectedPacks == nil {
+				return
+			}
+
+			order := models.Order{}
+			decodeData(t, resp, &order)
+			packs := map[int]int{}
+			for _, shipping := range order.Shipping {
+				packs[shipping.PackSize] = shipping.ShippingPackQuantity
+			}
+			if !maps.Equal(packs, tc.expectedPacks) {
+				t.Errorf("expected packs to be %v but got %v", tc.expectedPacks, packs)
+			}
+		})
+	}
+
+	resp := doRequest(t, http.MethodGet, fmt.Sprintf("http://localhost:%s/stock", conf.Port), conf.AdminToken, nil)
+	stock := []models.PackStock{}
+	decodeData(t, resp, &stock)
+	expected := []models.PackStock{{PackSize: 500, Quantity: 0}, {PackSize: 250, Quantity: 0}}
+	if len(stock) != len(expected) {
+		t.Fatalf("expected stock to be %+v but got %+v", expected, stock)
+	}
+	for i := range expected {
+		if stock[i].PackSize != expected[i].PackSize || stock[i].Quantity != expected[i].Quantity {
+			t.Errorf("expected stock to be %+v but got %+v", expected, stock)
+		}
+	}
+}
+
+func TestCreateOrderDoesNotOversell(t *testing.T) {
+	conf := getDefaultConfig()
+	createDBAndHTTPServer(t, &conf)
+
+	setPackStock(t, conf, 250, 3)
+	for _, size := range []int{500, 1000, 2000, 5000} {
+		setPackStock(t, conf, size, 0)
+	}
+
+	url := fmt.Sprintf("http://localhost:%s/orders", conf.Port)
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := doRequest(t, http.MethodPost, url, "", bytes.NewBufferString(`{ "number_of_items": 1 }`))
+			switch resp.StatusCode {
+			case http.StatusCreated:
+				mu.Lock()
+				created++
+				mu.Unlock()
+			case http.StatusConflict, http.StatusUnprocessableEntity:
+			default:
+				t.Errorf("unexpected status code %d", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 3 {
+		t.Errorf("expected 3 orders to be created with 3 packs in stock, got %d", created)
+	}
+}
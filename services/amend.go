@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+func (s service) AmendOrder(ctx context.Context, orderID, numberOfItems, version int) (*models.Order, error) {
+	if err := s.checkItems(numberOfItems); err != nil {
+		return nil, err
+	}
+
+	order, err := s.db.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case order.DeletedAt != nil:
+		return nil, fmt.Errorf("order %d: %w", orderID, database.ErrOrderDeleted)
+	case order.Version != version:
+		return nil, fmt.Errorf("order %d is at version %d: %w", orderID, order.Version, database.ErrVersionConflict)
+	case len(order.Lines) > 0:
+		return nil, fmt.Errorf("%w: order %d has lines", ErrNotAmendable, orderID)
+	case order.Status != models.OrderPending:
+		return nil, fmt.Errorf("%w: order %d is %s", ErrOrderNotPending, orderID, order.Status)
+	}
+
+	customer, err := s.orderCustomer(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, err := s.packingStrategy(order.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, packs, err := s.activeCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the packs of the order go back in stock when it is amended, so they
+	// can be used again to pack it
+	used := make(map[int]int)
+	for _, shipping := range order.Shipping {
+		used[shipping.PackSize] -= shipping.ShippingPackQuantity
+	}
+
+	order.NumberOfItems = numberOfItems
+	order.CatalogVersion = &catalog.Version
+	cost := catalog.ShipmentFee
+	order.Cost = &cost
+
+	orderShipping, _, err := s.packOrder(ctx, order, customer, strategy, OrderOptions{}, catalog.Version,
+		withStockUsed(packs, used))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.AmendOrder(ctx, order, orderShipping, version)
+	if err != nil {
+		return nil, fmt.Errorf("could not amend order %d: %w", orderID, err)
+	}
+
+	return s.db.GetOrder(ctx, orderID)
+}
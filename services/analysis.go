@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/pkg/packing"
+)
+
+// maxAnalysisRange is the most quantities a catalog analysis packs
+const maxAnalysisRange = 10_000
+
+// AnalysisOptions selects the pack sizes to analyse and the quantities to
+// pack with them. The packs of the catalog at CatalogVersion are analysed when
+// PackSizes is empty, or those of the active catalog when CatalogVersion is
+// zero too. MinItems defaults to 1 and MaxItems to twice the largest pack,
+// and MaxItems is at most the items an order can have.
+type AnalysisOptions struct {
+	PackSizes      []int
+	CatalogVersion int
+	MinItems       int
+	MaxItems       int
+}
+
+// CatalogAnalysis describes how a set of pack sizes ships the quantities
+// from MinItems to MaxItems with the least overshoot, then the fewest packs.
+// Only multiples of GCD can be shipped exactly, and FrobeniusNumber is the
+// largest multiple that cannot, it is nil when every multiple can.
+// Unreachable is the number of quantities in the range that cannot be shipped
+// exactly, and WorstOvershootItems the first quantity with the worst overshoot.
+type CatalogAnalysis struct {
+	PackSizes           []int            `json:"pack_sizes"`
+	CatalogVersion      *int             `json:"catalog_version"`
+	MinItems            int              `json:"min_items"`
+	MaxItems            int              `json:"max_items"`
+	GCD                 int              `json:"gcd"`
+	FrobeniusNumber     *int             `json:"frobenius_number"`
+	Unreachable         int              `json:"unreachable"`
+	WorstOvershoot      int              `json:"worst_overshoot"`
+	WorstOvershootItems int              `json:"worst_overshoot_items"`
+	AverageOvershoot    float64          `json:"average_overshoot"`
+	AveragePackCount    float64          `json:"average_pack_count"`
+	Curve               []OvershootPoint `json:"curve"`
+}
+
+// OvershootPoint is how a number of items ships
+type OvershootPoint struct {
+	NumberOfItems int `json:"number_of_items"`
+	Overshoot     int `json:"overshoot"`
+	PackCount     int `json:"pack_count"`
+}
+
+// AnalyzeCatalog packs every quantity in the range of options with a set of
+// pack sizes and reports which quantities ship exactly and how far over the
+// others ship
+func (s service) AnalyzeCatalog(ctx context.Context, options AnalysisOptions) (*CatalogAnalysis, error) {
+	analysis := &CatalogAnalysis{PackSizes: slices.Sorted(slices.Values(options.PackSizes))}
+	if len(analysis.PackSizes) == 0 {
+		version, packSizes, err := s.analysisCatalog(ctx, options.CatalogVersion)
+		if err != nil {
+			return nil, err
+		}
+		analysis.CatalogVersion, analysis.PackSizes = &version, packSizes
+	}
+
+	analysis.MinItems, analysis.MaxItems = options.MinItems, options.MaxItems
+	if analysis.MinItems == 0 {
+		analysis.MinItems = 1
+	}
+	if analysis.MaxItems == 0 {
+		analysis.MaxItems = 2 * analysis.PackSizes[len(analysis.PackSizes)-1]
+	}
+	if analysis.MinItems < 1 || analysis.MaxItems < analysis.MinItems ||
+		analysis.MaxItems-analysis.MinItems >= maxAnalysisRange {
+		return nil, fmt.Errorf("%w: %d to %d items, at most %d quantities can be analysed", ErrInvalidAnalysisRange,
+			analysis.MinItems, analysis.MaxItems, maxAnalysisRange)
+	}
+	if err := s.checkItems(analysis.MaxItems); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAnalysisRange, err)
+	}
+
+	solver, err := packing.NewOvershootSolver(ctx, analysis.PackSizes, s.limits.MaxTableSize)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis.GCD = solver.GCD()
+	if frobenius := solver.Frobenius(); frobenius >= 0 {
+		analysis.FrobeniusNumber = &frobenius
+	}
+	analysis.addCurve(solver)
+
+	return analysis, nil
+}
+
+// analysisCatalog returns the version and pack sizes of the catalog at
+// version, or of the active catalog when version is zero
+func (s service) analysisCatalog(ctx context.Context, version int) (int, []int, error) {
+	if version == 0 {
+		catalog, packs, err := s.activeCatalog(ctx)
+		if err != nil {
+			return 0, nil, err
+		}
+		return catalog.Version, packSizes(packs), nil
+	}
+
+	catalog, err := s.db.GetPackCatalog(ctx, version)
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil, fmt.Errorf("%w: %d", ErrUnknownCatalog, version)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	packs, err := catalogPacks(catalog)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return catalog.Version, packSizes(packs), nil
+}
+
+// addCurve packs every quantity in the range of the analysis and adds up how they ship
+func (a *CatalogAnalysis) addCurve(solver *packing.OvershootSolver) {
+	a.Curve = make([]OvershootPoint, 0, a.MaxItems-a.MinItems+1)
+	totalOvershoot, totalPacks := 0, 0
+	for n := a.MinItems; n <= a.MaxItems; n++ {
+		overshoot, packCount := solver.Pack(n)
+		a.Curve = append(a.Curve, OvershootPoint{NumberOfItems: n, Overshoot: overshoot, PackCount: packCount})
+		if overshoot > 0 {
+			a.Unreachable++
+		}
+		if overshoot > a.WorstOvershoot || n == a.MinItems {
+			a.WorstOvershoot, a.WorstOvershootItems = overshoot, n
+		}
+		totalOvershoot += overshoot
+		totalPacks += packCount
+	}
+
+	quantities := float64(len(a.Curve))
+	a.AverageOvershoot = math.Round(float64(totalOvershoot)/quantities*100) / 100
+	a.AveragePackCount = math.Round(float64(totalPacks)/quantities*100) / 100
+}
+
+// packSizes returns the sizes of packs, smallest first
+func packSizes(packs []Pack) []int {
+	sizes := make([]int, 0, len(packs))
+	for _, p := range packs {
+		sizes = append(sizes, p.Size)
+	}
+	slices.Sort(sizes)
+
+	return sizes
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/spankie/gymshark/pkg/packing"
+)
+
+func TestCatalogAnalysis(t *testing.T) {
+	testcases := []struct {
+		packSizes         []int
+		expectedGCD       int
+		expectedFrobenius int
+	}{
+		{packSizes: []int{6, 9, 20}, expectedGCD: 1, expectedFrobenius: 43},
+		{packSizes: []int{23, 31, 53}, expectedGCD: 1, expectedFrobenius: 326},
+		{packSizes: []int{4, 6}, expectedGCD: 2, expectedFrobenius: 2},
+		{packSizes: []int{250, 500, 1000, 2000, 5000}, expectedGCD: 250, expectedFrobenius: -1},
+		{packSizes: []int{7}, expectedGCD: 7, expectedFrobenius: -1},
+	}
+
+	for _, tc := range testcases {
+		t.Run(fmt.Sprint(tc.packSizes), func(t *testing.T) {
+			solver, err := packing.NewOvershootSolver(context.Background(), tc.packSizes, 0)
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if solver.GCD() != tc.expectedGCD || solver.Frobenius() != tc.expectedFrobenius {
+				t.Errorf("expected gcd %d and frobenius number %d, got %d and %d", tc.expectedGCD,
+					tc.expectedFrobenius, solver.GCD(), solver.Frobenius())
+			}
+
+			analysis := &CatalogAnalysis{PackSizes: tc.packSizes, MinItems: 1, MaxItems: 300}
+			analysis.addCurve(solver)
+			worst, unreachable := 0, 0
+			for _, point := range analysis.Curve {
+				overshoot, packCount := -point.NumberOfItems, 0
+				for size, count := range solvePacks(t, tc.packSizes, point.NumberOfItems) {
+					overshoot += size * count
+					packCount += count
+				}
+				if point.Overshoot != overshoot || point.PackCount != packCount {
+					t.Fatalf("expected %d items to ship %d over in %d packs as packing.Solve does, got %+v",
+						point.NumberOfItems, overshoot, packCount, point)
+				}
+				worst = max(worst, overshoot)
+				if overshoot > 0 {
+					unreachable++
+				}
+			}
+			if len(analysis.Curve) != 300 || analysis.WorstOvershoot != worst || analysis.Unreachable != unreachable {
+				t.Errorf("expected the worst overshoot %d and %d unreachable quantities, got %+v", worst, unreachable,
+					analysis)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCatalogRange(t *testing.T) {
+	s := service{limits: Limits{MaxItems: 1000}}
+	testcases := []struct {
+		name        string
+		options     AnalysisOptions
+		expectedErr error
+	}{
+		{name: "default range", options: AnalysisOptions{PackSizes: []int{250, 500}}},
+		{name: "up to the items limit", options: AnalysisOptions{PackSizes: []int{250, 500}, MaxItems: 1000}},
+		{name: "over the items limit", options: AnalysisOptions{PackSizes: []int{250, 500}, MaxItems: 1001},
+			expectedErr: ErrInvalidAnalysisRange},
+		{name: "largest quantity", options: AnalysisOptions{PackSizes: []int{250}, MinItems: math.MaxInt - 10,
+			MaxItems: math.MaxInt}, expectedErr: ErrInvalidAnalysisRange},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := s.AnalyzeCatalog(context.Background(), tc.options); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v but got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// orderCustomer returns the customer the order is placed for, it is nil for anonymous orders
+func (s service) orderCustomer(ctx context.Context, order *models.Order) (*models.Customer, error) {
+	if order.CustomerID == nil {
+		return nil, nil
+	}
+
+	customer, err := s.db.GetCustomer(ctx, *order.CustomerID)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownCustomer, *order.CustomerID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find customer: %w", err)
+	}
+
+	return customer, nil
+}
+
+// customerStrategy returns the name of the strategy an order asks for, or the
+// strategy of its customer when it asks for none
+func customerStrategy(name string, customer *models.Customer) string {
+	if name == "" && customer != nil && customer.Strategy != nil {
+		return *customer.Strategy
+	}
+
+	return name
+}
+
+// withoutRefused returns the packs the customer accepts their orders shipped in
+func withoutRefused(packs []Pack, customer *models.Customer) []Pack {
+	if customer == nil || len(customer.RefusedPackSizes) == 0 {
+		return packs
+	}
+
+	accepted := make([]Pack, 0, len(packs))
+	for _, p := range packs {
+		if !slices.Contains(customer.RefusedPackSizes, p.Size) {
+			accepted = append(accepted, p)
+		}
+	}
+
+	return accepted
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+func TestCustomerSettings(t *testing.T) {
+	fewest := StrategyFewestPacks
+	customer := &models.Customer{Strategy: &fewest, RefusedPackSizes: []int{250, 5000}}
+	packs := sizedPacks([]int{250, 500, 1000, 5000})
+
+	testcases := []struct {
+		name             string
+		strategy         string
+		customer         *models.Customer
+		expectedStrategy string
+		expectedSizes    []int
+	}{
+		{name: "anonymous order", expectedSizes: []int{250, 500, 1000, 5000}},
+		{name: "customer settings", customer: customer, expectedStrategy: StrategyFewestPacks,
+			expectedSizes: []int{500, 1000}},
+		{name: "order strategy", strategy: StrategyExact, customer: customer, expectedStrategy: StrategyExact,
+			expectedSizes: []int{500, 1000}},
+		{name: "customer without settings", customer: &models.Customer{}, expectedSizes: []int{250, 500, 1000, 5000}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if strategy := customerStrategy(tc.strategy, tc.customer); strategy != tc.expectedStrategy {
+				t.Errorf("expected strategy %q but got %q", tc.expectedStrategy, strategy)
+			}
+
+			var sizes []int
+			for _, p := range withoutRefused(packs, tc.customer) {
+				sizes = append(sizes, p.Size)
+			}
+			if !slices.Equal(sizes, tc.expectedSizes) {
+				t.Errorf("expected pack sizes %v but got %v", tc.expectedSizes, sizes)
+			}
+		})
+	}
+}
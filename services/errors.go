@@ -0,0 +1,49 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spankie/gymshark/pkg/packing"
+)
+
+var (
+	// ErrUnknownProduct is returned when an order line refers to a product that does not exist
+	ErrUnknownProduct = errors.New("unknown product")
+	// ErrUnknownCustomer is returned when an order is placed for a customer that does not exist
+	ErrUnknownCustomer = errors.New("unknown customer")
+	// ErrTooManyItems is returned when an order or quote has more items than allowed
+	ErrTooManyItems = errors.New("too many items")
+	// ErrPackingLimit is returned when packing an order takes more time or
+	// memory than allowed
+	ErrPackingLimit = packing.ErrLimitExceeded
+	// ErrUnknownStatus is returned when an order status is not recognised
+	ErrUnknownStatus = errors.New("unknown order status")
+	// ErrIllegalTransition is returned when an order cannot move to a status from its current one
+	ErrIllegalTransition = errors.New("illegal order status transition")
+	// ErrNotAmendable is returned when an order cannot be amended, e.g. because it has lines
+	ErrNotAmendable = errors.New("order cannot be amended")
+	// ErrOrderNotPending is returned when an order is amended after it was packed
+	ErrOrderNotPending = errors.New("only pending orders can be amended")
+	// ErrOrderNotImported is returned for the orders of an all or nothing import
+	// that were not created because another order of the import failed
+	ErrOrderNotImported = errors.New("order not imported because another order failed")
+	// ErrImportStopped is returned for the orders of an import that were not
+	// packed because the client went away or the import ran out of time
+	ErrImportStopped = errors.New("order not imported because the import stopped")
+	// ErrUnknownCatalog is returned when orders are repacked with a catalog
+	// that does not exist or is the catalog of a product
+	ErrUnknownCatalog = errors.New("unknown global pack catalog")
+	// ErrUnknownRepackMode is returned when a repack job has a mode that is not recognised
+	ErrUnknownRepackMode = errors.New("unknown repack mode")
+	// ErrInvalidPackSearch is returned when the pack sizes a catalog
+	// recommendation searches are out of range
+	ErrInvalidPackSearch = errors.New("invalid pack size search")
+	// ErrInvalidAnalysisRange is returned when the quantities a catalog
+	// analysis packs are out of range
+	ErrInvalidAnalysisRange = errors.New("invalid quantity range")
+	// ErrNoOrderHistory is returned when there are no orders to recommend a catalog for
+	ErrNoOrderHistory = errors.New("no orders to recommend a pack catalog for")
+
+	errPackingTimeout = fmt.Errorf("%w: packing took too long", ErrPackingLimit)
+)
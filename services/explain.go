@@ -0,0 +1,81 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/spankie/gymshark/pkg/packing"
+)
+
+// MaxExplain is the most alternative packings an explanation can list
+const MaxExplain = 10
+
+// Alternative is one of the packings ranked for an order. Cost is the price
+// of the packs, without the shipment fee.
+type Alternative struct {
+	Packs      []PackCount `json:"packs"`
+	TotalItems int         `json:"total_items"`
+	Leftover   int         `json:"leftover"`
+	PackCount  int         `json:"pack_count"`
+	Cost       int         `json:"cost"`
+}
+
+// Explanation lists the best packings a strategy found for an order, or a
+// line of an order, best first. Criteria are what the strategy ranks packings
+// by in order, and Reason is why the first packing, the one used, ranks
+// before the second, e.g. "same overshoot, fewer packs".
+type Explanation struct {
+	ProductID    *int          `json:"product_id,omitempty"`
+	Strategy     string        `json:"strategy"`
+	Criteria     []string      `json:"criteria"`
+	Reason       string        `json:"reason"`
+	Alternatives []Alternative `json:"alternatives"`
+}
+
+// packExplained packs numberOfItems with the strategy, checking at most
+// maxTableSize totals. When explain is more than zero it also explains the
+// choice among the best explain packings.
+func packExplained(ctx context.Context, strategy PackingStrategy, packs []Pack,
+	numberOfItems, explain, maxTableSize int) (map[int]int, *Explanation, error) {
+	if explain < 1 {
+		shippingPacks, err := strategy.Pack(ctx, packs, numberOfItems, maxTableSize)
+		return shippingPacks, nil, err
+	}
+
+	ranked, ok := strategy.(rankedStrategy)
+	if !ok {
+		return nil, nil, fmt.Errorf("the %s strategy cannot explain its packings", strategy.Name())
+	}
+
+	problem := packing.Problem{Items: numberOfItems, Packs: packs, Objective: ranked.objective(),
+		MaxTableSize: maxTableSize}
+	ranking, err := packing.Rank(ctx, problem, min(explain, MaxExplain))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	explanation := &Explanation{Strategy: strategy.Name(), Criteria: ranking.Criteria, Reason: ranking.Reason}
+	for _, result := range ranking.Results {
+		explanation.Alternatives = append(explanation.Alternatives,
+			newAlternative(numberOfItems, packs, result.Counts()))
+	}
+
+	return ranking.Results[0].Counts(), explanation, nil
+}
+
+// newAlternative summarises the packs found for numberOfItems, largest pack first
+func newAlternative(numberOfItems int, packs []Pack, shippingPacks map[int]int) Alternative {
+	alternative := Alternative{Packs: make([]PackCount, 0, len(shippingPacks))}
+	for size, quantity := range shippingPacks {
+		alternative.Packs = append(alternative.Packs, PackCount{PackSize: size, Quantity: quantity})
+		alternative.TotalItems += size * quantity
+		alternative.PackCount += quantity
+	}
+	slices.SortFunc(alternative.Packs, func(a, b PackCount) int { return cmp.Compare(b.PackSize, a.PackSize) })
+	alternative.Leftover = alternative.TotalItems - numberOfItems
+	alternative.Cost = packingCost(packs, shippingPacks)
+
+	return alternative
+}
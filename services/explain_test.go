@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestPackExplained(t *testing.T) {
+	defaultPacks := sizedPacks([]int{5000, 2000, 1000, 500, 250})
+	cheapSmallPacks := []Pack{{Size: 3, Cost: 1}, {Size: 5, Cost: 10}}
+
+	testcases := []struct {
+		name              string
+		strategy          string
+		packs             []Pack
+		order             int
+		explain           int
+		expectedCriteria  []string
+		expectedReason    string
+		expectedLeftovers []int
+		expectedCounts    []int
+		expectedCosts     []int
+	}{
+		{
+			name:              "least overshoot",
+			strategy:          StrategyLeastOvershoot,
+			packs:             defaultPacks,
+			order:             501,
+			explain:           3,
+			expectedCriteria:  []string{"overshoot", "pack count"},
+			expectedReason:    "lower overshoot",
+			expectedLeftovers: []int{249, 499, 749},
+			expectedCounts:    []int{2, 1, 2},
+			expectedCosts:     []int{0, 0, 0},
+		},
+		{
+			name:              "fewest packs",
+			strategy:          StrategyFewestPacks,
+			packs:             defaultPacks,
+			order:             501,
+			explain:           2,
+			expectedCriteria:  []string{"pack count", "overshoot"},
+			expectedReason:    "same pack count, lower overshoot",
+			expectedLeftovers: []int{499, 1499},
+			expectedCounts:    []int{1, 1},
+			expectedCosts:     []int{0, 0},
+		},
+		{
+			name:              "least cost",
+			strategy:          StrategyLeastCost,
+			packs:             cheapSmallPacks,
+			order:             5,
+			explain:           10,
+			expectedCriteria:  []string{"cost", "overshoot", "pack count"},
+			expectedReason:    "lower cost",
+			expectedLeftovers: []int{1, 4, 0, 3},
+			expectedCounts:    []int{2, 3, 1, 2},
+			expectedCosts:     []int{2, 3, 10, 11},
+		},
+		{
+			name:              "exact has a single packing",
+			strategy:          StrategyExact,
+			packs:             cheapSmallPacks,
+			order:             8,
+			explain:           3,
+			expectedCriteria:  []string{"pack count"},
+			expectedReason:    "only packing found",
+			expectedLeftovers: []int{0},
+			expectedCounts:    []int{2},
+			expectedCosts:     []int{11},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			strategy, err := GetPackingStrategy(tc.strategy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			shippingPacks, explanation, err := packExplained(context.Background(), strategy, tc.packs, tc.order, tc.explain, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if explanation.Strategy != tc.strategy {
+				t.Errorf("expected strategy %s but got %s", tc.strategy, explanation.Strategy)
+			}
+			if !slices.Equal(explanation.Criteria, tc.expectedCriteria) {
+				t.Errorf("expected criteria %v but got %v", tc.expectedCriteria, explanation.Criteria)
+			}
+			if explanation.Reason != tc.expectedReason {
+				t.Errorf("expected reason %q but got %q", tc.expectedReason, explanation.Reason)
+			}
+
+			var leftovers, counts, costs []int
+			for _, alternative := range explanation.Alternatives {
+				leftovers = append(leftovers, alternative.Leftover)
+				counts = append(counts, alternative.PackCount)
+				costs = append(costs, alternative.Cost)
+			}
+			if !slices.Equal(leftovers, tc.expectedLeftovers) {
+				t.Errorf("expected leftovers %v but got %v", tc.expectedLeftovers, leftovers)
+			}
+			if !slices.Equal(counts, tc.expectedCounts) {
+				t.Errorf("expected pack counts %v but got %v", tc.expectedCounts, counts)
+			}
+			if !slices.Equal(costs, tc.expectedCosts) {
+				t.Errorf("expected costs %v but got %v", tc.expectedCosts, costs)
+			}
+
+			// the first alternative is the packing used
+			used := newAlternative(tc.order, tc.packs, shippingPacks)
+			if !slices.Equal(used.Packs, explanation.Alternatives[0].Packs) {
+				t.Errorf("expected the packing used %v to be the first alternative %v",
+					used.Packs, explanation.Alternatives[0].Packs)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// ImportedOrder is an order of an import, Err is why it was not created
+type ImportedOrder struct {
+	Order *models.Order
+	Err   error
+}
+
+func (s service) ImportOrders(ctx context.Context, orders []*models.Order, atomic bool) ([]ImportedOrder, error) {
+	catalog, packs, err := s.activeCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := make([]ImportedOrder, len(orders))
+	newOrders := make([]database.NewOrder, 0, len(orders))
+	// the orders share the stock, packs used by an order are not available to the next ones
+	used := make(map[int]int)
+	for i, order := range orders {
+		imported[i].Order = order
+		orderShipping, _, err := s.packNewOrder(ctx, order, OrderOptions{}, catalog, withStockUsed(packs, used))
+		if ctx.Err() != nil {
+			// the client is gone or the import ran out of time, the orders left are not packed
+			if atomic {
+				return nil, context.Cause(ctx)
+			}
+			return importStopped(ctx, imported, orders, i), nil
+		}
+		if err != nil {
+			imported[i].Err = err
+			continue
+		}
+
+		if !atomic {
+			if err := s.db.CreateOrder(ctx, order, orderShipping); err != nil {
+				imported[i].Err = fmt.Errorf("could not create order: %w", err)
+				continue
+			}
+		}
+		newOrders = append(newOrders, database.NewOrder{Order: order, Shipping: orderShipping})
+		for _, shipping := range orderShipping {
+			used[shipping.PackSize] += shipping.ShippingPackQuantity
+		}
+	}
+
+	if !atomic {
+		return imported, nil
+	}
+
+	if len(newOrders) < len(orders) {
+		for i := range imported {
+			if imported[i].Err == nil {
+				imported[i].Err = ErrOrderNotImported
+			}
+		}
+		return imported, nil
+	}
+
+	if err := s.db.CreateOrders(ctx, newOrders); err != nil {
+		return nil, fmt.Errorf("could not create orders: %w", err)
+	}
+
+	return imported, nil
+}
+
+// importStopped marks the orders of imported from the ith as not imported
+// because the import stopped, the orders before it were already created
+func importStopped(ctx context.Context, imported []ImportedOrder, orders []*models.Order, i int) []ImportedOrder {
+	err := fmt.Errorf("%w: %w", ErrImportStopped, context.Cause(ctx))
+	for ; i < len(orders); i++ {
+		imported[i] = ImportedOrder{Order: orders[i], Err: err}
+	}
+
+	return imported
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// importDB creates orders until it has created stopAfter of them, then stops the import
+type importDB struct {
+	database.Service
+	stopAfter int
+	stop      context.CancelFunc
+	created   int
+}
+
+func (db *importDB) GetActivePackCatalog(context.Context) (*models.PackCatalog, error) {
+	return &models.PackCatalog{Version: 1, Packs: []models.ShippingPack{{Quantity: 250}, {Quantity: 500}}}, nil
+}
+
+func (db *importDB) CreateOrder(context.Context, *models.Order, []*models.OrderShipping) error {
+	db.created++
+	if db.created == db.stopAfter {
+		db.stop()
+	}
+
+	return nil
+}
+
+func TestImportOrdersStopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := &importDB{stopAfter: 2, stop: cancel}
+	s := service{db: db, logger: slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		strategy: strategies[StrategyLeastOvershoot]}
+
+	orders := []*models.Order{{NumberOfItems: 1}, {NumberOfItems: 251}, {NumberOfItems: 501}, {NumberOfItems: 751}}
+	imported, err := s.ImportOrders(ctx, orders, false)
+	if err != nil {
+		t.Fatalf("expected the orders created before the import stopped, got error %v", err)
+	}
+	if len(imported) != len(orders) || db.created != 2 {
+		t.Fatalf("expected 2 of %d orders created, got %d of %d", len(orders), db.created, len(imported))
+	}
+	for i, result := range imported {
+		if result.Order != orders[i] {
+			t.Errorf("expected order %d to be reported in place", i)
+		}
+		if stopped := errors.Is(result.Err, ErrImportStopped); stopped != (i >= 2) {
+			t.Errorf("expected order %d stopped to be %t, got error %v", i, i >= 2, result.Err)
+		}
+	}
+}
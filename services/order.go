@@ -2,52 +2,292 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/spankie/gymshark/database"
 	"github.com/spankie/gymshark/database/models"
 )
 
 type service struct {
-	db     database.Service
-	logger *slog.Logger
+	db       database.Service
+	logger   *slog.Logger
+	strategy PackingStrategy
+	limits   Limits
 }
 
-func NewOrderService(db database.Service, logger *slog.Logger) OrderService {
+// Limits bound the work done to pack an order or a quote, a zero limit is no limit
+type Limits struct {
+	// MaxItems is the most items an order or a quote can have
+	MaxItems int
+	// MaxTableSize is the most totals the packing of an order can check,
+	// it bounds the memory used to pack an order
+	MaxTableSize int
+	// PackingTimeout is the longest packing an order can take
+	PackingTimeout time.Duration
+}
+
+// NewOrderService creates an order service that packs orders with the given
+// strategy unless the order asks for another one
+func NewOrderService(db database.Service, logger *slog.Logger, strategy PackingStrategy, limits Limits) OrderService {
 	return service{
-		db:     db,
-		logger: logger.With("name", "order_service"),
+		db:       db,
+		logger:   logger.With("name", "order_service"),
+		strategy: strategy,
+		limits:   limits,
 	}
 }
 
-func (s service) CreateOrder(ctx context.Context, order *models.Order) error {
-	packs, err := s.db.GetAvailableShippingPacks(ctx)
+func (s service) CreateOrder(ctx context.Context, order *models.Order, options OrderOptions) ([]Explanation, error) {
+	catalog, packs, err := s.activeCatalog(ctx)
 	if err != nil {
-		return fmt.Errorf("could not find shipping packs: %w", err)
+		return nil, err
 	}
 
-	// get a slice of only the quantity to be used in calculating the shipping packs
-	packSlice := make([]int, 0, len(packs))
-	for _, v := range packs {
-		packSlice = append(packSlice, v.Quantity)
-	}
-
-	if len(packSlice) < 1 {
-		return fmt.Errorf("no packs to ship")
+	orderShipping, explanations, err := s.packNewOrder(ctx, order, options, catalog, packs)
+	if err != nil {
+		return nil, err
 	}
 
-	shippingPacks := findOptimalPacks(packSlice, order.NumberOfItems)
-	err = s.db.CreateOrder(ctx, order, getOrderShipping(shippingPacks))
+	err = s.db.CreateOrder(ctx, order, orderShipping)
 	if err != nil {
 		err := fmt.Errorf("Error creating order: %w", err)
 		s.logger.Error(err.Error())
-		return err
+		return nil, err
+	}
+
+	return explanations, nil
+}
+
+// packNewOrder packs an order that is being created with the packs of the
+// global catalog, and sets the catalog version, strategy and cost of the order
+func (s service) packNewOrder(ctx context.Context, order *models.Order, options OrderOptions,
+	catalog *models.PackCatalog, packs []Pack) ([]*models.OrderShipping, []Explanation, error) {
+	if err := s.checkItems(orderItems(order)); err != nil {
+		return nil, nil, err
+	}
+
+	customer, err := s.orderCustomer(ctx, order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	strategy, err := s.packingStrategy(customerStrategy(order.Strategy, customer))
+	if err != nil {
+		return nil, nil, err
+	}
+	order.Strategy = strategy.Name()
+
+	// an order is a single shipment, so the global catalog's fee is charged once
+	order.CatalogVersion = &catalog.Version
+	cost := catalog.ShipmentFee
+	order.Cost = &cost
+
+	return s.packOrder(ctx, order, customer, strategy, options, catalog.Version, packs)
+}
+
+// orderItems returns the number of items of an order or the total of its lines
+func orderItems(order *models.Order) int {
+	if len(order.Lines) == 0 {
+		return order.NumberOfItems
+	}
+
+	items := 0
+	for _, line := range order.Lines {
+		items += line.Quantity
+	}
+
+	return items
+}
+
+// checkItems returns ErrTooManyItems when numberOfItems is over the limit
+func (s service) checkItems(numberOfItems int) error {
+	if s.limits.MaxItems > 0 && numberOfItems > s.limits.MaxItems {
+		return fmt.Errorf("%w: %d items, at most %d can be packed", ErrTooManyItems, numberOfItems, s.limits.MaxItems)
 	}
 
 	return nil
 }
 
+// packingContext returns a context that stops packing once it has used up the
+// packing time
+func (s service) packingContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.limits.PackingTimeout > 0 {
+		return context.WithTimeoutCause(ctx, s.limits.PackingTimeout, errPackingTimeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// packingErr returns why packing was stopped when ctx is done, so running out
+// of packing time can be told apart from a cancelled request
+func packingErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+
+	return err
+}
+
+// packOrder packs the lines of the order, or its number of items when it has
+// no lines, and adds the price of the packs to the order cost. The packs the
+// customer refuses are not used.
+func (s service) packOrder(ctx context.Context, order *models.Order, customer *models.Customer,
+	strategy PackingStrategy, options OrderOptions, globalVersion int,
+	globalPacks []Pack) ([]*models.OrderShipping, []Explanation, error) {
+	ctx, cancel := s.packingContext(ctx)
+	defer cancel()
+
+	if len(order.Lines) > 0 {
+		explanations, err := s.packOrderLines(ctx, order, customer, strategy, options, globalVersion, globalPacks)
+		if err != nil {
+			return nil, nil, packingErr(ctx, err)
+		}
+		return nil, explanations, nil
+	}
+
+	globalPacks = withoutRefused(globalPacks, customer)
+	shippingPacks, explanation, err := packExplained(ctx, strategy, globalPacks, order.NumberOfItems, options.Explain,
+		s.limits.MaxTableSize)
+	if err != nil {
+		return nil, nil, packingErr(ctx, err)
+	}
+	*order.Cost += packingCost(globalPacks, shippingPacks)
+
+	var explanations []Explanation
+	if explanation != nil {
+		explanations = append(explanations, *explanation)
+	}
+
+	return getOrderShipping(shippingPacks), explanations, nil
+}
+
+// packingStrategy returns the strategy with the given name, or the default
+// strategy of the service when name is empty
+func (s service) packingStrategy(name string) (PackingStrategy, error) {
+	if name == "" {
+		return s.strategy, nil
+	}
+
+	return GetPackingStrategy(name)
+}
+
+// packOrderLines packs each line of the order on its own, using the catalog
+// of the line's product or the global catalog when the product has none
+func (s service) packOrderLines(ctx context.Context, order *models.Order, customer *models.Customer,
+	strategy PackingStrategy, options OrderOptions, globalVersion int, globalPacks []Pack) ([]Explanation, error) {
+	var explanations []Explanation
+	order.NumberOfItems = 0
+	// the lines share the stock, packs used by a line are not available to the next ones
+	used := make(map[int]int)
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		product, err := s.db.GetProduct(ctx, line.ProductID)
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownProduct, line.ProductID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not find product: %w", err)
+		}
+
+		line.CatalogVersion = globalVersion
+		packs := globalPacks
+		if product.Catalog != nil {
+			line.CatalogVersion = product.Catalog.Version
+			packs, err = catalogPacks(product.Catalog)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		packs = withoutRefused(packs, customer)
+		shippingPacks, explanation, err := packExplained(ctx, strategy, withStockUsed(packs, used), line.Quantity,
+			options.Explain, s.limits.MaxTableSize)
+		if err != nil {
+			return nil, fmt.Errorf("product %d: %w", line.ProductID, err)
+		}
+		if explanation != nil {
+			explanation.ProductID = &line.ProductID
+			explanations = append(explanations, *explanation)
+		}
+		for size, n := range shippingPacks {
+			used[size] += n
+		}
+		for _, shipping := range getOrderShipping(shippingPacks) {
+			line.Shipping = append(line.Shipping, *shipping)
+		}
+		order.NumberOfItems += line.Quantity
+		*order.Cost += packingCost(packs, shippingPacks)
+	}
+
+	return explanations, nil
+}
+
+// activeCatalog returns the active catalog and its packs
+func (s service) activeCatalog(ctx context.Context) (*models.PackCatalog, []Pack, error) {
+	catalog, err := s.db.GetActivePackCatalog(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not find shipping packs: %w", err)
+	}
+
+	packs, err := catalogPacks(catalog)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return catalog, packs, nil
+}
+
+// catalogPacks returns the catalog packs to be used in calculating the shipping packs
+func catalogPacks(catalog *models.PackCatalog) ([]Pack, error) {
+	packs := make([]Pack, 0, len(catalog.Packs))
+	for _, v := range catalog.Packs {
+		packs = append(packs, Pack{Size: v.Quantity, Cost: v.UnitCost, Stock: v.Stock})
+	}
+
+	if len(packs) < 1 {
+		return nil, fmt.Errorf("no packs to ship")
+	}
+
+	return packs, nil
+}
+
+// withStockUsed returns a copy of packs with the packs already used removed from their stock
+func withStockUsed(packs []Pack, used map[int]int) []Pack {
+	left := make([]Pack, len(packs))
+	for i, p := range packs {
+		left[i] = p
+		if p.Stock != nil {
+			stock := max(*p.Stock-used[p.Size], 0)
+			left[i].Stock = &stock
+		}
+	}
+
+	return left
+}
+
+// sizedPacks creates packs without a price from pack sizes
+func sizedPacks(packSizes []int) []Pack {
+	packs := make([]Pack, 0, len(packSizes))
+	for _, size := range packSizes {
+		packs = append(packs, Pack{Size: size})
+	}
+
+	return packs
+}
+
+// packingCost returns the total price of the packs used to ship an order
+func packingCost(packs []Pack, shippingPacks map[int]int) int {
+	cost := 0
+	for _, pack := range packs {
+		cost += pack.Cost * shippingPacks[pack.Size]
+	}
+
+	return cost
+}
+
 func getOrderShipping(orderShippingPacks map[int]int) []*models.OrderShipping {
 	orderShipping := make([]*models.OrderShipping, 0, len(orderShippingPacks))
 	for k, v := range orderShippingPacks {
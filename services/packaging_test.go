@@ -1,6 +1,23 @@
 package services
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/spankie/gymshark/pkg/packing"
+)
+
+// solvePacks returns the packs of packSizes that ship n items with the least
+// overshoot, using the fewest packs when overshoot is equal
+func solvePacks(t *testing.T, packSizes []int, n int) map[int]int {
+	t.Helper()
+	result, err := packing.Solve(context.Background(), packing.Problem{Items: n, Packs: sizedPacks(packSizes)})
+	if err != nil {
+		t.Fatalf("could not pack %d items in %v: %v", n, packSizes, err)
+	}
+
+	return result.Counts()
+}
 
 func TestCalculatePack(t *testing.T) {
 	testcases := []struct {
@@ -69,7 +86,7 @@ func TestCalculatePack(t *testing.T) {
 	var packSizes = []int{5000, 2000, 1000, 500, 250}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := findOptimalPacks(packSizes, tc.order)
+			result := solvePacks(t, packSizes, tc.order)
 			if len(result) != len(tc.expectedResult) {
 				t.Errorf("number of entries should match, expected: %v; got %v", len(tc.expectedResult), len(result))
 			}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+// PackCount is the number of packs of one size used to ship an order
+type PackCount struct {
+	PackSize int `json:"pack_size"`
+	Quantity int `json:"quantity"`
+}
+
+// QuoteOptions describes the order to quote. The packs of the active catalog
+// are used when PackSizes is empty, and the default strategy when Strategy is empty.
+// Explicit pack sizes have no price, so only catalog quotes have a cost.
+// Explain is the number of ranked packings to explain the choice with, the
+// choice is not explained when it is zero.
+type QuoteOptions struct {
+	NumberOfItems int
+	PackSizes     []int
+	Strategy      string
+	Explain       int
+}
+
+// Quote describes how an order would be packed without creating it
+type Quote struct {
+	NumberOfItems  int          `json:"number_of_items"`
+	CatalogVersion *int         `json:"catalog_version"`
+	Strategy       string       `json:"strategy"`
+	PackSizes      []int        `json:"pack_sizes"`
+	Packs          []PackCount  `json:"packs"`
+	TotalItems     int          `json:"total_items"`
+	Leftover       int          `json:"leftover"`
+	PackCount      int          `json:"pack_count"`
+	Cost           int          `json:"cost"`
+	Explanation    *Explanation `json:"explanation,omitempty"`
+}
+
+// Quote calculates the packs needed to ship an order without creating it
+func (s service) Quote(ctx context.Context, options QuoteOptions) (*Quote, error) {
+	if err := s.checkItems(options.NumberOfItems); err != nil {
+		return nil, err
+	}
+
+	strategy, err := s.packingStrategy(options.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog *models.PackCatalog
+	packs := sizedPacks(options.PackSizes)
+	if len(packs) == 0 {
+		catalog, packs, err = s.activeCatalog(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	packingCtx, cancel := s.packingContext(ctx)
+	defer cancel()
+	shippingPacks, explanation, err := packExplained(packingCtx, strategy, packs, options.NumberOfItems,
+		options.Explain, s.limits.MaxTableSize)
+	if err != nil {
+		return nil, packingErr(packingCtx, err)
+	}
+
+	quote := newQuote(options.NumberOfItems, packs, shippingPacks)
+	quote.Strategy = strategy.Name()
+	quote.Explanation = explanation
+	if catalog != nil {
+		quote.CatalogVersion = &catalog.Version
+		quote.Cost += catalog.ShipmentFee
+	}
+
+	return quote, nil
+}
+
+// newQuote summarises the packs found for numberOfItems, largest pack first
+func newQuote(numberOfItems int, packs []Pack, shippingPacks map[int]int) *Quote {
+	alternative := newAlternative(numberOfItems, packs, shippingPacks)
+	quote := &Quote{
+		NumberOfItems: numberOfItems,
+		PackSizes:     make([]int, 0, len(packs)),
+		Packs:         alternative.Packs,
+		TotalItems:    alternative.TotalItems,
+		Leftover:      alternative.Leftover,
+		PackCount:     alternative.PackCount,
+		Cost:          alternative.Cost,
+	}
+
+	for _, pack := range packs {
+		quote.PackSizes = append(quote.PackSizes, pack.Size)
+	}
+
+	return quote
+}
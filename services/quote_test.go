@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewQuote(t *testing.T) {
+	packSizes := []int{5000, 2000, 1000, 500, 250}
+	quote := newQuote(12001, sizedPacks(packSizes), solvePacks(t, packSizes, 12001))
+
+	expectedPacks := []PackCount{
+		{PackSize: 5000, Quantity: 2},
+		{PackSize: 2000, Quantity: 1},
+		{PackSize: 250, Quantity: 1},
+	}
+	if !slices.Equal(quote.Packs, expectedPacks) {
+		t.Errorf("expected packs to be %v but got %v", expectedPacks, quote.Packs)
+	}
+	if quote.TotalItems != 12250 {
+		t.Errorf("expected total items to be 12250 but got %d", quote.TotalItems)
+	}
+	if quote.Leftover != 249 {
+		t.Errorf("expected leftover to be 249 but got %d", quote.Leftover)
+	}
+	if quote.PackCount != 4 {
+		t.Errorf("expected pack count to be 4 but got %d", quote.PackCount)
+	}
+}
+
+func TestQuoteLimits(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testcases := []struct {
+		name        string
+		ctx         context.Context
+		limits      Limits
+		options     QuoteOptions
+		expectedErr error
+	}{
+		{
+			name:        "within limits",
+			ctx:         context.Background(),
+			limits:      Limits{MaxItems: 1000, MaxTableSize: 10_000, PackingTimeout: time.Minute},
+			options:     QuoteOptions{NumberOfItems: 1000, PackSizes: []int{250, 500}},
+			expectedErr: nil,
+		},
+		{
+			name:        "too many items",
+			ctx:         context.Background(),
+			limits:      Limits{MaxItems: 1000},
+			options:     QuoteOptions{NumberOfItems: 1001, PackSizes: []int{250, 500}},
+			expectedErr: ErrTooManyItems,
+		},
+		{
+			name:        "table too large",
+			ctx:         context.Background(),
+			limits:      Limits{MaxTableSize: 1_000_000},
+			options:     QuoteOptions{NumberOfItems: 1_000_000_000, PackSizes: []int{99991, 99989}},
+			expectedErr: ErrPackingLimit,
+		},
+		{
+			name:        "packing timeout",
+			ctx:         context.Background(),
+			limits:      Limits{PackingTimeout: time.Nanosecond},
+			options:     QuoteOptions{NumberOfItems: 1_000_000_000, PackSizes: []int{997, 991, 983}},
+			expectedErr: ErrPackingLimit,
+		},
+		{
+			name:        "cancelled request",
+			ctx:         cancelled,
+			limits:      Limits{PackingTimeout: time.Minute},
+			options:     QuoteOptions{NumberOfItems: 1_000_000_000, PackSizes: []int{997, 991, 983}},
+			expectedErr: context.Canceled,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewOrderService(nil, logger, strategies[StrategyLeastOvershoot], tc.limits)
+			_, err := s.Quote(tc.ctx, tc.options)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v but got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/spankie/gymshark/database/models"
+	"github.com/spankie/gymshark/pkg/packing"
+)
+
+const (
+	// defaultRecommendations is the number of pack size sets recommended when the options have no Top
+	defaultRecommendations = 5
+	// maxRecommendSets is the most pack size sets a recommendation searches
+	maxRecommendSets = 100_000
+	// maxRecommendWork is the most totals the tables of all the pack size sets searched can check
+	maxRecommendWork = 200_000_000
+)
+
+// RecommendOptions bounds the pack size sets a recommendation searches. The
+// sets have Sizes pack sizes from MinSize to MaxSize, Step apart, and are
+// scored against the orders placed from From until To. Sizes, MinSize and
+// MaxSize default to the number of packs, smallest and largest pack of the
+// active catalog, and Step defaults to MinSize. Top is the number of sets
+// recommended.
+type RecommendOptions struct {
+	From    time.Time
+	To      time.Time
+	Sizes   int
+	MinSize int
+	MaxSize int
+	Step    int
+	Top     int
+}
+
+// PackSetScore is how a set of pack sizes would have shipped the order
+// history with the least overshoot, then the fewest packs, as orders are packed
+// with the least_overshoot strategy. Overshoot is the items shipped beyond the
+// items ordered and PackCount the number of packs shipped.
+type PackSetScore struct {
+	PackSizes        []int   `json:"pack_sizes"`
+	Overshoot        int     `json:"overshoot"`
+	OvershootPercent float64 `json:"overshoot_percent"`
+	PackCount        int     `json:"pack_count"`
+}
+
+// CatalogRecommendations are the pack size sets that would have shipped the
+// order history best, best first, and the score of the active catalog to
+// compare them with
+type CatalogRecommendations struct {
+	Orders          int            `json:"orders"`
+	ItemsOrdered    int            `json:"items_ordered"`
+	SetsSearched    int            `json:"sets_searched"`
+	Current         PackSetScore   `json:"current"`
+	Recommendations []PackSetScore `json:"recommendations"`
+}
+
+// RecommendCatalogs searches every set of pack sizes allowed by options for
+// the sets that would have shipped the orders placed in the range best
+func (s service) RecommendCatalogs(ctx context.Context, options RecommendOptions) (*CatalogRecommendations, error) {
+	_, packs, err := s.activeCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := packSizes(packs)
+
+	candidates, sizes, err := options.candidates(current)
+	if err != nil {
+		return nil, err
+	}
+	sets := binomial(len(candidates), sizes, maxRecommendSets)
+	if sets > maxRecommendSets {
+		return nil, fmt.Errorf("%w: more than %d pack size sets to search, narrow the sizes or use a larger step",
+			ErrPackingLimit, maxRecommendSets)
+	}
+
+	quantities, err := s.db.GetOrderQuantities(ctx, options.From, options.To)
+	if err != nil {
+		return nil, fmt.Errorf("could not get order history: %w", err)
+	}
+	if len(quantities) == 0 {
+		return nil, ErrNoOrderHistory
+	}
+
+	recommendations := &CatalogRecommendations{SetsSearched: sets}
+	for _, q := range quantities {
+		recommendations.Orders += q.Orders
+		recommendations.ItemsOrdered += q.NumberOfItems * q.Orders
+	}
+	if recommendations.Current, _, err = scorePackSet(ctx, current, quantities, s.limits.MaxTableSize); err != nil {
+		return nil, err
+	}
+	if recommendations.Recommendations, err = searchPackSets(ctx, candidates, sizes, options.top(), quantities,
+		s.limits.MaxTableSize); err != nil {
+		return nil, err
+	}
+
+	setOvershootPercent(&recommendations.Current, recommendations.ItemsOrdered)
+	for i := range recommendations.Recommendations {
+		setOvershootPercent(&recommendations.Recommendations[i], recommendations.ItemsOrdered)
+	}
+
+	return recommendations, nil
+}
+
+// candidates returns the pack sizes the sets are made of and the number of
+// sizes of each set, with the defaults taken from the current pack sizes. It
+// fails with ErrPackingLimit when there are more than maxRecommendSets pack
+// sizes, as there are then at least as many sets to search.
+func (o RecommendOptions) candidates(current []int) ([]int, int, error) {
+	sizes, minSize, maxSize, step := o.Sizes, o.MinSize, o.MaxSize, o.Step
+	if sizes == 0 {
+		sizes = len(current)
+	}
+	if minSize == 0 {
+		minSize = current[0]
+	}
+	if maxSize == 0 {
+		maxSize = current[len(current)-1]
+	}
+	if step == 0 {
+		step = minSize
+	}
+	if sizes < 1 || minSize < 1 || step < 1 || maxSize < minSize {
+		return nil, 0, fmt.Errorf("%w: %d sizes from %d to %d, %d apart", ErrInvalidPackSearch, sizes, minSize,
+			maxSize, step)
+	}
+
+	count := (maxSize-minSize)/step + 1
+	if count > maxRecommendSets {
+		return nil, 0, fmt.Errorf("%w: more than %d pack sizes from %d to %d, use a larger step",
+			ErrPackingLimit, maxRecommendSets, minSize, maxSize)
+	}
+
+	candidates := make([]int, count)
+	for i := range candidates {
+		candidates[i] = minSize + i*step
+	}
+
+	return candidates, min(sizes, len(candidates)), nil
+}
+
+func (o RecommendOptions) top() int {
+	if o.Top == 0 {
+		return defaultRecommendations
+	}
+
+	return o.Top
+}
+
+// searchPackSets scores every set of sizes candidates and returns the top
+// best sets. Sets that score the same keep the order they are searched in.
+func searchPackSets(ctx context.Context, candidates []int, sizes, top int, quantities []models.OrderQuantity,
+	maxTableSize int) ([]PackSetScore, error) {
+	best := make([]PackSetScore, 0, top+1)
+	work := 0
+	set := make([]int, sizes)
+	for i := range set {
+		set[i] = i
+	}
+
+	for {
+		packSizes := make([]int, sizes)
+		for i, c := range set {
+			packSizes[i] = candidates[c]
+		}
+		score, checked, err := scorePackSet(ctx, packSizes, quantities, maxTableSize)
+		if err != nil {
+			return nil, err
+		}
+		if work += checked; work > maxRecommendWork {
+			return nil, fmt.Errorf("%w: the search checks more than %d totals, narrow the sizes or use a larger step",
+				ErrPackingLimit, maxRecommendWork)
+		}
+
+		i := slices.IndexFunc(best, func(b PackSetScore) bool { return compareScores(score, b) < 0 })
+		if i < 0 {
+			i = len(best)
+		}
+		if i < top {
+			best = slices.Insert(best, i, score)
+			best = best[:min(len(best), top)]
+		}
+
+		if !nextCombination(set, len(candidates)) {
+			return best, nil
+		}
+	}
+}
+
+// compareScores orders pack size sets by overshoot, then pack count
+func compareScores(a, b PackSetScore) int {
+	if n := cmp.Compare(a.Overshoot, b.Overshoot); n != 0 {
+		return n
+	}
+
+	return cmp.Compare(a.PackCount, b.PackCount)
+}
+
+// scorePackSet packs every quantity with the least overshoot, then the fewest
+// packs, and returns the score of the pack sizes and the totals checked. It
+// fails with ErrPackingLimit when the pack sizes need maxTableSize totals or more.
+func scorePackSet(ctx context.Context, packSizes []int, quantities []models.OrderQuantity,
+	maxTableSize int) (PackSetScore, int, error) {
+	solver, err := packing.NewOvershootSolver(ctx, packSizes, maxTableSize)
+	if err != nil {
+		return PackSetScore{}, 0, err
+	}
+
+	score := PackSetScore{PackSizes: packSizes}
+	for _, q := range quantities {
+		overshoot, packCount := solver.Pack(q.NumberOfItems)
+		score.Overshoot += overshoot * q.Orders
+		score.PackCount += packCount * q.Orders
+	}
+
+	return score, solver.TableSize(), nil
+}
+
+// setOvershootPercent sets the overshoot of a score as a percent of the items ordered
+func setOvershootPercent(score *PackSetScore, itemsOrdered int) {
+	if itemsOrdered > 0 {
+		percent := float64(score.Overshoot) * 100 / float64(itemsOrdered)
+		score.OvershootPercent = math.Round(percent*100) / 100
+	}
+}
+
+// binomial returns the number of ways to choose k of n, or limit+1 when it is over limit
+func binomial(n, k, limit int) int {
+	result := 1
+	for i := 1; i <= k; i++ {
+		// result is the number of ways to choose i-1 of n-k+i-1, the division is exact
+		result = result * (n - k + i) / i
+		if result > limit {
+			return limit + 1
+		}
+	}
+
+	return result
+}
+
+// nextCombination moves set, increasing indexes below n, to the next
+// combination in lexicographic order, it returns false after the last one
+func nextCombination(set []int, n int) bool {
+	k := len(set)
+	i := k - 1
+	for i >= 0 && set[i] == n-k+i {
+		i--
+	}
+	if i < 0 {
+		return false
+	}
+
+	set[i]++
+	for j := i + 1; j < k; j++ {
+		set[j] = set[j-1] + 1
+	}
+
+	return true
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+func TestScorePackSet(t *testing.T) {
+	var quantities []models.OrderQuantity
+	for n := 1; n <= 2000; n += 7 {
+		quantities = append(quantities, models.OrderQuantity{NumberOfItems: n, Orders: n%3 + 1})
+	}
+	quantities = append(quantities, models.OrderQuantity{NumberOfItems: 12001, Orders: 2},
+		models.OrderQuantity{NumberOfItems: 500000, Orders: 1})
+
+	for _, packSizes := range [][]int{{250, 500, 1000, 2000, 5000}, {23, 31, 53}, {300, 750}, {7}} {
+		t.Run(fmt.Sprint(packSizes), func(t *testing.T) {
+			expected := PackSetScore{PackSizes: packSizes}
+			for _, q := range quantities {
+				for size, count := range solvePacks(t, packSizes, q.NumberOfItems) {
+					expected.Overshoot += size * count * q.Orders
+					expected.PackCount += count * q.Orders
+				}
+				expected.Overshoot -= q.NumberOfItems * q.Orders
+			}
+
+			score, _, err := scorePackSet(context.Background(), packSizes, quantities, 0)
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if fmt.Sprint(score) != fmt.Sprint(expected) {
+				t.Errorf("expected the score of packing.Solve %+v but got %+v", expected, score)
+			}
+		})
+	}
+}
+
+func TestSearchPackSets(t *testing.T) {
+	quantities := []models.OrderQuantity{{NumberOfItems: 300, Orders: 5}, {NumberOfItems: 650, Orders: 1}}
+
+	testcases := []struct {
+		name     string
+		sizes    int
+		top      int
+		expected string
+	}{
+		// 300 ships in a 300 pack and 650 in 700 at best, in 300 + 400 or in 300 + 300 + 100
+		{name: "two sizes", sizes: 2, top: 3, expected: "[{[300 400] 50 0 7} {[100 300] 50 0 8} {[200 300] 50 0 8}]"},
+		{name: "one size", sizes: 1, top: 2, expected: "[{[100] 50 0 22} {[300] 250 0 8}]"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			best, err := searchPackSets(context.Background(), []int{100, 200, 300, 400}, tc.sizes, tc.top, quantities, 0)
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if fmt.Sprint(best) != tc.expected {
+				t.Errorf("expected %s but got %v", tc.expected, best)
+			}
+		})
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	set, count := []int{0, 1, 2}, 1
+	for nextCombination(set, 6) {
+		count++
+	}
+
+	if count != 20 || binomial(6, 3, 100) != 20 {
+		t.Errorf("expected 20 combinations of 3 of 6, got %d and %d", count, binomial(6, 3, 100))
+	}
+	if binomial(40, 20, 1000) != 1001 {
+		t.Errorf("expected binomial to stop past the limit, got %d", binomial(40, 20, 1000))
+	}
+}
+
+func TestRecommendCandidates(t *testing.T) {
+	current := []int{250, 500, 1000}
+	testcases := []struct {
+		name        string
+		options     RecommendOptions
+		expected    string
+		expectedErr error
+	}{
+		{name: "current catalog", expected: "[250 500 750 1000]"},
+		{name: "step", options: RecommendOptions{Sizes: 2, MinSize: 100, MaxSize: 400, Step: 150},
+			expected: "[100 250 400]"},
+		{name: "too many sizes", options: RecommendOptions{MinSize: 1, MaxSize: math.MaxInt, Step: 1},
+			expectedErr: ErrPackingLimit},
+		{name: "largest size", options: RecommendOptions{MinSize: math.MaxInt - 2, MaxSize: math.MaxInt, Step: 1},
+			expected: fmt.Sprint([]int{math.MaxInt - 2, math.MaxInt - 1, math.MaxInt})},
+		{name: "invalid range", options: RecommendOptions{MinSize: 500, MaxSize: 100},
+			expectedErr: ErrInvalidPackSearch},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			candidates, _, err := tc.options.candidates(current)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v but got %v", tc.expectedErr, err)
+			}
+			if err == nil && fmt.Sprint(candidates) != tc.expected {
+				t.Errorf("expected %s but got %v", tc.expected, candidates)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+const (
+	// repackBatchSize is the number of orders a repack job packs and saves at a time
+	repackBatchSize = 100
+	// RepackStaleAfter is the shortest time a running repack job can go
+	// without saving a batch before it is considered stopped and can be resumed
+	RepackStaleAfter = 5 * time.Minute
+)
+
+func (s service) CreateRepackJob(ctx context.Context, job *models.RepackJob) error {
+	catalog, err := s.db.GetPackCatalog(ctx, job.CatalogVersion)
+	if errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("%w: %d", ErrUnknownCatalog, job.CatalogVersion)
+	}
+	if err != nil {
+		return err
+	}
+	if catalog.ProductID != nil {
+		return fmt.Errorf("%w: catalog %d is for product %d", ErrUnknownCatalog, catalog.Version, *catalog.ProductID)
+	}
+
+	if job.Mode == "" {
+		job.Mode = models.RepackWhatIf
+	}
+	// open orders are repacked unless the filter selects other orders
+	if job.Filter.Status == "" {
+		job.Filter.Status = models.OrderPending
+	}
+	if job.Mode != models.RepackWhatIf && job.Mode != models.RepackApply {
+		return fmt.Errorf("%w: %s", ErrUnknownRepackMode, job.Mode)
+	}
+
+	return s.db.CreateRepackJob(ctx, job)
+}
+
+func (s service) ClaimRepackJob(ctx context.Context, jobID int) (*models.RepackJob, error) {
+	return s.db.ClaimRepackJob(ctx, jobID, s.repackStaleAfter())
+}
+
+// repackStaleAfter is how long a running repack job can go without saving a
+// batch before it is considered stopped. A batch can take the packing timeout
+// of each of its orders, so a job is given twice that, and at least
+// RepackStaleAfter, before another worker can resume it.
+func (s service) repackStaleAfter() time.Duration {
+	return max(RepackStaleAfter, 2*repackBatchSize*s.limits.PackingTimeout)
+}
+
+func (s service) RunRepackJob(ctx context.Context, job *models.RepackJob) error {
+	s.logger.Info("repack job started", "job", job.ID, "mode", job.Mode, "last_order_id", job.LastOrderID)
+
+	status, message := models.RepackCompleted, (*string)(nil)
+	err := s.runRepackJob(ctx, job)
+	if err != nil {
+		status = models.RepackFailed
+		errMessage := err.Error()
+		message = &errMessage
+	}
+
+	// the job is marked as failed even when it stopped because ctx was cancelled
+	finishErr := s.db.FinishRepackJob(context.WithoutCancel(ctx), job, status, message)
+	s.logger.Info("repack job finished", "job", job.ID, "status", job.Status, "processed", job.Processed)
+
+	return errors.Join(err, finishErr)
+}
+
+// runRepackJob packs the orders of the job after its last order, a batch at a time
+func (s service) runRepackJob(ctx context.Context, job *models.RepackJob) error {
+	catalog, err := s.db.GetPackCatalog(ctx, job.CatalogVersion)
+	if err != nil {
+		return fmt.Errorf("could not get repack catalog: %w", err)
+	}
+	packs, err := catalogPacks(catalog)
+	if err != nil {
+		return err
+	}
+
+	for {
+		orders, err := s.db.GetRepackOrders(ctx, job.Filter, job.LastOrderID, repackBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		batch := make([]database.RepackedOrder, 0, len(orders))
+		for i := range orders {
+			repacked, err := s.repackOrder(ctx, job, &orders[i], catalog, packs)
+			if err != nil {
+				return err
+			}
+			batch = append(batch, repacked)
+		}
+
+		if err := s.db.SaveRepackBatch(ctx, job, batch); err != nil {
+			return fmt.Errorf("could not save repack batch: %w", err)
+		}
+		s.logger.Debug("repack batch saved", "job", job.ID, "processed", job.Processed, "total", job.Total)
+	}
+}
+
+// repackOrder packs an order with the packs of the catalog, the packs of the
+// order count as in stock. An order that cannot be packed gets an error in
+// its result, the error is returned when the order was not packed for another
+// reason, e.g. the database is down.
+func (s service) repackOrder(ctx context.Context, job *models.RepackJob, order *models.Order,
+	catalog *models.PackCatalog, packs []Pack) (database.RepackedOrder, error) {
+	result := &models.RepackResult{JobID: job.ID, OrderID: order.ID, OldShipping: order.Shipping}
+	if result.OldShipping == nil {
+		result.OldShipping = []models.OrderShipping{}
+	}
+	result.OldOvershoot, result.OldPacks = shippingStats(order.NumberOfItems, order.Shipping)
+	repacked := database.RepackedOrder{Order: order, Version: order.Version, Result: result}
+
+	if job.Mode == models.RepackApply && order.Status != models.OrderPending {
+		message := fmt.Sprintf("%s: order %d is %s", ErrOrderNotPending, order.ID, order.Status)
+		result.Error = &message
+		return repacked, nil
+	}
+
+	shipping, err := s.packRepackOrder(ctx, order, catalog, packs)
+	if isPackingFailure(err) {
+		message := err.Error()
+		result.Error = &message
+		return repacked, nil
+	}
+	if err != nil {
+		return repacked, fmt.Errorf("could not repack order %d: %w", order.ID, err)
+	}
+
+	repacked.Shipping = shipping
+	result.NewShipping = make([]models.OrderShipping, 0, len(shipping))
+	for _, v := range shipping {
+		result.NewShipping = append(result.NewShipping, *v)
+	}
+	newOvershoot, newPacks := shippingStats(order.NumberOfItems, result.NewShipping)
+	result.NewOvershoot, result.NewPacks = &newOvershoot, &newPacks
+	result.Changed = !maps.Equal(shippingPacks(result.OldShipping), shippingPacks(result.NewShipping))
+
+	return repacked, nil
+}
+
+// packRepackOrder packs an order again with the strategy it was packed with,
+// it sets the catalog version and cost of the order to those of the new packing
+func (s service) packRepackOrder(ctx context.Context, order *models.Order, catalog *models.PackCatalog,
+	packs []Pack) ([]*models.OrderShipping, error) {
+	customer, err := s.orderCustomer(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := s.packingStrategy(order.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[int]int)
+	for _, shipping := range order.Shipping {
+		used[shipping.PackSize] -= shipping.ShippingPackQuantity
+	}
+
+	order.CatalogVersion = &catalog.Version
+	cost := catalog.ShipmentFee
+	order.Cost = &cost
+	shipping, _, err := s.packOrder(ctx, order, customer, strategy, OrderOptions{}, catalog.Version,
+		withStockUsed(packs, used))
+
+	return shipping, err
+}
+
+// isPackingFailure reports whether err is why an order could not be packed,
+// rather than a failure to pack it
+func isPackingFailure(err error) bool {
+	for _, known := range []error{ErrUnknownStrategy, ErrUnknownCustomer, ErrNoPacking, ErrPackingLimit} {
+		if errors.Is(err, known) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shippingPacks returns the number of packs of each size of a shipping
+func shippingPacks(shipping []models.OrderShipping) map[int]int {
+	packs := make(map[int]int, len(shipping))
+	for _, s := range shipping {
+		packs[s.PackSize] += s.ShippingPackQuantity
+	}
+
+	return packs
+}
+
+// shippingStats returns the items a shipping has beyond numberOfItems and its number of packs
+func shippingStats(numberOfItems int, shipping []models.OrderShipping) (int, int) {
+	items, packs := 0, 0
+	for _, s := range shipping {
+		items += s.PackSize * s.ShippingPackQuantity
+		packs += s.ShippingPackQuantity
+	}
+
+	return items - numberOfItems, packs
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+func TestRepackOrder(t *testing.T) {
+	s := service{logger: slog.New(slog.NewJSONHandler(io.Discard, nil)), strategy: strategies[StrategyLeastOvershoot]}
+	catalog := &models.PackCatalog{Version: 2, ShipmentFee: 100}
+	packs := sizedPacks([]int{300, 500})
+	shipped := []models.OrderShipping{{PackSize: 500, ShippingPackQuantity: 1}}
+
+	testcases := []struct {
+		name              string
+		mode              string
+		order             models.Order
+		expectedChanged   bool
+		expectedShipping  string
+		expectedOvershoot int
+		expectedErr       string
+	}{
+		{name: "better packing", mode: models.RepackWhatIf,
+			order:           models.Order{NumberOfItems: 251, Status: models.OrderPending, Shipping: shipped},
+			expectedChanged: true, expectedShipping: "map[300:1]", expectedOvershoot: 49},
+		{name: "same packing", mode: models.RepackApply,
+			order:            models.Order{NumberOfItems: 500, Status: models.OrderPending, Shipping: shipped},
+			expectedShipping: "map[500:1]"},
+		{name: "what if of a shipped order", mode: models.RepackWhatIf,
+			order:           models.Order{NumberOfItems: 251, Status: models.OrderShipped, Shipping: shipped},
+			expectedChanged: true, expectedShipping: "map[300:1]", expectedOvershoot: 49},
+		{name: "apply to a shipped order", mode: models.RepackApply,
+			order:       models.Order{NumberOfItems: 251, Status: models.OrderShipped, Shipping: shipped},
+			expectedErr: "only pending orders"},
+		{name: "no packing", mode: models.RepackWhatIf,
+			order: models.Order{NumberOfItems: 251, Strategy: StrategyExact, Status: models.OrderPending,
+				Shipping: shipped},
+			expectedErr: "no packing"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &models.RepackJob{ID: 1, Mode: tc.mode}
+			repacked, err := s.repackOrder(context.Background(), job, &tc.order, catalog, packs)
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			result := repacked.Result
+			if result.OldOvershoot != 500-tc.order.NumberOfItems || result.OldPacks != 1 {
+				t.Errorf("expected the old packing of the order, got %+v", result)
+			}
+			if tc.expectedErr != "" {
+				if result.Error == nil || !strings.Contains(*result.Error, tc.expectedErr) {
+					t.Errorf("expected error %q but got %v", tc.expectedErr, result.Error)
+				}
+				return
+			}
+
+			if result.Error != nil || result.Changed != tc.expectedChanged {
+				t.Fatalf("expected changed %v without error, got %+v", tc.expectedChanged, result)
+			}
+			if packs := fmt.Sprint(shippingPacks(result.NewShipping)); packs != tc.expectedShipping {
+				t.Errorf("expected shipping %s but got %s", tc.expectedShipping, packs)
+			}
+			if *result.NewOvershoot != tc.expectedOvershoot || *result.NewPacks != 1 {
+				t.Errorf("expected overshoot %d with 1 pack, got %d with %d", tc.expectedOvershoot,
+					*result.NewOvershoot, *result.NewPacks)
+			}
+			if *tc.order.CatalogVersion != catalog.Version || *tc.order.Cost != catalog.ShipmentFee {
+				t.Errorf("expected the order to be packed with catalog %d, got %+v", catalog.Version, tc.order)
+			}
+		})
+	}
+}
+
+func TestRepackStaleAfter(t *testing.T) {
+	testcases := []struct {
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{timeout: 0, expected: RepackStaleAfter},
+		{timeout: time.Second, expected: RepackStaleAfter},
+		{timeout: 5 * time.Second, expected: 1000 * time.Second},
+	}
+
+	for _, tc := range testcases {
+		s := service{limits: Limits{PackingTimeout: tc.timeout}}
+		if got := s.repackStaleAfter(); got != tc.expected {
+			t.Errorf("expected a %s packing timeout to be stale after %s, got %s", tc.timeout, tc.expected, got)
+		}
+	}
+}
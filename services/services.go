@@ -6,6 +6,41 @@ import (
 	"github.com/spankie/gymshark/database/models"
 )
 
+// OrderOptions changes how an order is created. Explain is the number of
+// ranked packings to explain the packing of the order with, the packing is not
+// explained when it is zero.
+type OrderOptions struct {
+	Explain int
+}
+
 type OrderService interface {
-	CreateOrder(ctx context.Context, order *models.Order) error
+	CreateOrder(ctx context.Context, order *models.Order, options OrderOptions) ([]Explanation, error)
+	Quote(ctx context.Context, options QuoteOptions) (*Quote, error)
+	// TransitionOrder moves an order to status and returns the updated order
+	TransitionOrder(ctx context.Context, orderID int, status string) (*models.Order, error)
+	// AmendOrder packs an order again for numberOfItems, when the order is
+	// still at version, and returns the updated order
+	AmendOrder(ctx context.Context, orderID, numberOfItems, version int) (*models.Order, error)
+	// ImportOrders packs and creates orders, each packed with the stock the
+	// orders before it left. When atomic is set no order is created unless
+	// every order can be, otherwise each order is created on its own and the
+	// orders left when ctx ends fail with ErrImportStopped.
+	ImportOrders(ctx context.Context, orders []*models.Order, atomic bool) ([]ImportedOrder, error)
+	// CreateRepackJob creates a job that packs the orders selected by its
+	// filter again with the packs of its catalog
+	CreateRepackJob(ctx context.Context, job *models.RepackJob) error
+	// ClaimRepackJob marks a repack job as running, it returns
+	// database.ErrRepackJobNotResumable when the job is completed or is
+	// already running
+	ClaimRepackJob(ctx context.Context, jobID int) (*models.RepackJob, error)
+	// RunRepackJob runs a claimed repack job until every order is processed,
+	// a stopped job resumes after the last batch it saved. The job is
+	// completed or failed when it returns.
+	RunRepackJob(ctx context.Context, job *models.RepackJob) error
+	// RecommendCatalogs returns the sets of pack sizes that would have
+	// shipped the order history with the least overshoot, then the fewest packs
+	RecommendCatalogs(ctx context.Context, options RecommendOptions) (*CatalogRecommendations, error)
+	// AnalyzeCatalog reports which quantities a set of pack sizes ships
+	// exactly and how far over it ships the others
+	AnalyzeCatalog(ctx context.Context, options AnalysisOptions) (*CatalogAnalysis, error)
 }
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/spankie/gymshark/database"
+	"github.com/spankie/gymshark/database/models"
+)
+
+// orderTransitions are the statuses an order can move to from each status.
+// Orders move forward from pending to delivered, and can be cancelled until
+// they are shipped.
+var orderTransitions = map[string][]string{
+	models.OrderPending:   {models.OrderPacked, models.OrderCancelled},
+	models.OrderPacked:    {models.OrderShipped, models.OrderCancelled},
+	models.OrderShipped:   {models.OrderDelivered},
+	models.OrderDelivered: {},
+	models.OrderCancelled: {},
+}
+
+// checkTransition returns an error when an order cannot move from one status to the other
+func checkTransition(from, to string) error {
+	if _, ok := orderTransitions[to]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownStatus, to)
+	}
+	if !slices.Contains(orderTransitions[from], to) {
+		return fmt.Errorf("%w: from %s to %s", ErrIllegalTransition, from, to)
+	}
+
+	return nil
+}
+
+func (s service) TransitionOrder(ctx context.Context, orderID int, status string) (*models.Order, error) {
+	order, err := s.db.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.DeletedAt != nil {
+		return nil, fmt.Errorf("order %d: %w", orderID, database.ErrOrderDeleted)
+	}
+
+	if err := checkTransition(order.Status, status); err != nil {
+		return nil, err
+	}
+
+	err = s.db.TransitionOrder(ctx, &models.OrderTransition{OrderID: orderID, From: &order.Status, To: status})
+	if err != nil {
+		return nil, fmt.Errorf("could not move order %d to %s: %w", orderID, status, err)
+	}
+
+	return s.db.GetOrder(ctx, orderID)
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spankie/gymshark/database/models"
+)
+
+func TestCheckTransition(t *testing.T) {
+	testcases := []struct {
+		from        string
+		to          string
+		expectedErr error
+	}{
+		{from: models.OrderPending, to: models.OrderPacked},
+		{from: models.OrderPacked, to: models.OrderShipped},
+		{from: models.OrderShipped, to: models.OrderDelivered},
+		{from: models.OrderPending, to: models.OrderCancelled},
+		{from: models.OrderPacked, to: models.OrderCancelled},
+		{from: models.OrderPending, to: models.OrderShipped, expectedErr: ErrIllegalTransition},
+		{from: models.OrderShipped, to: models.OrderCancelled, expectedErr: ErrIllegalTransition},
+		{from: models.OrderDelivered, to: models.OrderPending, expectedErr: ErrIllegalTransition},
+		{from: models.OrderCancelled, to: models.OrderPacked, expectedErr: ErrIllegalTransition},
+		{from: models.OrderPacked, to: models.OrderPacked, expectedErr: ErrIllegalTransition},
+		{from: models.OrderPending, to: "lost", expectedErr: ErrUnknownStatus},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.from+" to "+tc.to, func(t *testing.T) {
+			err := checkTransition(tc.from, tc.to)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v but got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
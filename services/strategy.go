@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spankie/gymshark/pkg/packing"
+)
+
+// names of the available packing strategies
+const (
+	StrategyLeastOvershoot = string(packing.LeastOvershoot)
+	StrategyFewestPacks    = string(packing.FewestPacks)
+	StrategyLeastCost      = string(packing.LeastCost)
+	StrategyExact          = string(packing.Exact)
+)
+
+var (
+	// ErrUnknownStrategy is returned when a packing strategy name is not recognised
+	ErrUnknownStrategy = errors.New("unknown packing strategy")
+	// ErrNoPacking is returned when the packs cannot ship the order under the strategy
+	ErrNoPacking = packing.ErrNoPacking
+)
+
+// Pack is a pack size a strategy can use and what it costs to ship. Stock
+// is the number of packs available, it is nil when the supply is unlimited.
+type Pack = packing.Pack
+
+// PackingStrategy decides which combination of packs ships an order
+type PackingStrategy interface {
+	// Name identifies the strategy in the configuration, requests and stored orders
+	Name() string
+	// Pack returns the number of packs of each size used to ship numberOfItems.
+	// It fails with ErrPackingLimit instead of checking maxTableSize totals or
+	// more, unless maxTableSize is zero, and stops and returns the context error
+	// when ctx is done.
+	Pack(ctx context.Context, packs []Pack, numberOfItems, maxTableSize int) (map[int]int, error)
+}
+
+var strategies = map[string]PackingStrategy{
+	StrategyLeastOvershoot: objectiveStrategy(packing.LeastOvershoot),
+	StrategyFewestPacks:    objectiveStrategy(packing.FewestPacks),
+	StrategyLeastCost:      objectiveStrategy(packing.LeastCost),
+	StrategyExact:          objectiveStrategy(packing.Exact),
+}
+
+// GetPackingStrategy returns the strategy with the given name, the empty
+// name is the least overshoot strategy
+func GetPackingStrategy(name string) (PackingStrategy, error) {
+	if name == "" {
+		name = StrategyLeastOvershoot
+	}
+
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, name)
+	}
+
+	return strategy, nil
+}
+
+// rankedStrategy is a strategy that packs orders with the first packing of
+// an objective of the packing package, so it can rank the other packings too
+type rankedStrategy interface {
+	PackingStrategy
+	objective() packing.Objective
+}
+
+// objectiveStrategy packs orders with the best packing of an objective
+type objectiveStrategy packing.Objective
+
+func (s objectiveStrategy) Name() string { return string(s) }
+
+func (s objectiveStrategy) Pack(ctx context.Context, packs []Pack,
+	numberOfItems, maxTableSize int) (map[int]int, error) {
+	result, err := packing.Solve(ctx, packing.Problem{Items: numberOfItems, Packs: packs, Objective: s.objective(),
+		MaxTableSize: maxTableSize})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Counts(), nil
+}
+
+func (s objectiveStrategy) objective() packing.Objective { return packing.Objective(s) }
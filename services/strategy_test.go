@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+)
+
+func TestPackingStrategies(t *testing.T) {
+	defaultPacks := sizedPacks([]int{5000, 2000, 1000, 500, 250})
+	cheapSmallPacks := []Pack{{Size: 3, Cost: 1}, {Size: 5, Cost: 10}}
+
+	testcases := []struct {
+		name           string
+		strategy       string
+		packs          []Pack
+		order          int
+		expectedResult map[int]int
+		expectedErr    error
+	}{
+		{
+			name:           "least overshoot",
+			strategy:       StrategyLeastOvershoot,
+			packs:          defaultPacks,
+			order:          501,
+			expectedResult: map[int]int{500: 1, 250: 1},
+		},
+		{
+			name:           "fewest packs",
+			strategy:       StrategyFewestPacks,
+			packs:          defaultPacks,
+			order:          501,
+			expectedResult: map[int]int{1000: 1},
+		},
+		{
+			name:           "fewest packs for a large order",
+			strategy:       StrategyFewestPacks,
+			packs:          defaultPacks,
+			order:          12001,
+			expectedResult: map[int]int{5000: 3},
+		},
+		{
+			name:           "least cost",
+			strategy:       StrategyLeastCost,
+			packs:          cheapSmallPacks,
+			order:          5,
+			expectedResult: map[int]int{3: 2},
+		},
+		{
+			name:           "least cost without prices has the least overshoot",
+			strategy:       StrategyLeastCost,
+			packs:          defaultPacks,
+			order:          501,
+			expectedResult: map[int]int{500: 1, 250: 1},
+		},
+		{
+			name:           "exact",
+			strategy:       StrategyExact,
+			packs:          cheapSmallPacks,
+			order:          8,
+			expectedResult: map[int]int{5: 1, 3: 1},
+		},
+		{
+			name:        "exact fails",
+			strategy:    StrategyExact,
+			packs:       cheapSmallPacks,
+			order:       7,
+			expectedErr: ErrNoPacking,
+		},
+		{
+			name:           "least overshoot with limited stock",
+			strategy:       StrategyLeastOvershoot,
+			packs:          stockedPacks(defaultPacks, map[int]int{250: 0, 500: 1}),
+			order:          751,
+			expectedResult: map[int]int{1000: 1},
+		},
+		{
+			name:           "fewest packs with limited stock",
+			strategy:       StrategyFewestPacks,
+			packs:          stockedPacks(defaultPacks, map[int]int{5000: 2}),
+			order:          12001,
+			expectedResult: map[int]int{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:           "exact with limited stock",
+			strategy:       StrategyExact,
+			packs:          stockedPacks(cheapSmallPacks, map[int]int{3: 1}),
+			order:          13,
+			expectedResult: map[int]int{5: 2, 3: 1},
+		},
+		{
+			name:        "not enough stock",
+			strategy:    StrategyLeastOvershoot,
+			packs:       stockedPacks(cheapSmallPacks, map[int]int{3: 1, 5: 1}),
+			order:       9,
+			expectedErr: ErrNoPacking,
+		},
+		{
+			name:        "no packs",
+			strategy:    StrategyLeastOvershoot,
+			order:       7,
+			expectedErr: ErrNoPacking,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			strategy, err := GetPackingStrategy(tc.strategy)
+			if err != nil {
+				t.Fatalf("expected nil error getting strategy but got: %v", err)
+			}
+
+			result, err := strategy.Pack(context.Background(), tc.packs, tc.order, 0)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v but got: %v", tc.expectedErr, err)
+			}
+			if !maps.Equal(result, tc.expectedResult) {
+				t.Errorf("expected packs to be %v but got %v", tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestGetPackingStrategy(t *testing.T) {
+	strategy, err := GetPackingStrategy("")
+	if err != nil || strategy.Name() != StrategyLeastOvershoot {
+		t.Errorf("expected the empty name to be the least overshoot strategy, got %v, %v", strategy, err)
+	}
+
+	_, err = GetPackingStrategy("cheapest")
+	if !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("expected unknown strategy error but got: %v", err)
+	}
+}
+
+// stockedPacks returns a copy of packs with the given stock for some sizes
+func stockedPacks(packs []Pack, stock map[int]int) []Pack {
+	stocked := make([]Pack, len(packs))
+	for i, p := range packs {
+		stocked[i] = p
+		if n, ok := stock[p.Size]; ok {
+			stocked[i].Stock = &n
+		}
+	}
+
+	return stocked
+}
+
+func TestPackingStrategiesLargeOrders(t *testing.T) {
+	defaultPacks := sizedPacks([]int{5000, 2000, 1000, 500, 250})
+
+	testcases := []struct {
+		name           string
+		strategy       string
+		order          int
+		expectedResult map[int]int
+	}{
+		{
+			name:           "least overshoot",
+			strategy:       StrategyLeastOvershoot,
+			order:          1_000_000_000_001,
+			expectedResult: map[int]int{5000: 200_000_000, 250: 1},
+		},
+		{
+			name:           "fewest packs",
+			strategy:       StrategyFewestPacks,
+			order:          1_000_000_004_001,
+			expectedResult: map[int]int{5000: 200_000_001},
+		},
+		{
+			name:           "exact",
+			strategy:       StrategyExact,
+			order:          1_000_000_002_750,
+			expectedResult: map[int]int{5000: 200_000_000, 2000: 1, 500: 1, 250: 1},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			strategy, err := GetPackingStrategy(tc.strategy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := strategy.Pack(context.Background(), defaultPacks, tc.order, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !maps.Equal(result, tc.expectedResult) {
+				t.Errorf("expected %v but got %v", tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func BenchmarkPackingStrategies(b *testing.B) {
+	defaultPacks := sizedPacks([]int{5000, 2000, 1000, 500, 250})
+
+	for _, name := range []string{StrategyLeastOvershoot, StrategyFewestPacks, StrategyLeastCost, StrategyExact} {
+		for _, n := range []int{1_000, 1_000_000, 1_000_000_000, 1_000_000_000_000} {
+			b.Run(fmt.Sprintf("%s/%d", name, n), func(b *testing.B) {
+				strategy := strategies[name]
+				b.ReportAllocs()
+				for range b.N {
+					if _, err := strategy.Pack(context.Background(), defaultPacks, n, 0); err != nil {
+						b.Fatalf("unexpected error: %v", err)
+					}
+				}
+			})
+		}
+	}
+}